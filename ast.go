@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/dot"
+	"github.com/UsamaHameed/monkey-interpreter/cfg"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/optimize"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+)
+
+// readSource reads the named file, or standard input if name is empty
+// or "-".
+func readSource(name string) (string, error) {
+    if name == "" || name == "-" {
+        src, err := io.ReadAll(os.Stdin)
+        return string(src), err
+    }
+
+    src, err := os.ReadFile(name)
+    return string(src), err
+}
+
+func displayName(name string) string {
+    if name == "" || name == "-" {
+        return "<stdin>"
+    }
+    return name
+}
+
+// parseSource parses src, printing any parser errors to stderr.
+func parseSource(name, src string) (*ast.Program, bool) {
+    p := parser.New(lexer.New(src))
+    program := p.ParseProgram()
+
+    if len(p.ErrorList()) != 0 {
+        for _, err := range p.ErrorList() {
+            fmt.Fprintf(os.Stderr, "%s:%s\n", name, err)
+        }
+        return nil, false
+    }
+
+    return program, true
+}
+
+func runAST(args []string) int {
+    flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+    asJSON := flags.Bool("json", false, "print the syntax tree as JSON")
+    asDOT := flags.Bool("dot", false, "print the syntax tree as a Graphviz DOT graph")
+    asCFG := flags.Bool("cfg", false, "print the control-flow graphs as a Graphviz DOT graph")
+    tailCalls := flags.Bool("tailcalls", false, "mark calls in tail position before printing")
+    flags.Usage = func() {
+        fmt.Fprintf(os.Stderr, "usage: monkey ast [flags] [file]\n")
+        flags.PrintDefaults()
+    }
+
+    if err := flags.Parse(args); err != nil {
+        return 2
+    }
+
+    outputs := 0
+    for _, set := range []bool{*asJSON, *asDOT, *asCFG} {
+        if set {
+            outputs++
+        }
+    }
+    if outputs > 1 {
+        fmt.Fprintf(os.Stderr, "monkey ast: only one of -json, -dot and -cfg may be given\n")
+        return 2
+    }
+    if *tailCalls && !*asJSON && !*asDOT {
+        fmt.Fprintf(os.Stderr, "monkey ast: -tailcalls needs -json or -dot, which show the marks\n")
+        return 2
+    }
+
+    src, err := readSource(flags.Arg(0))
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+
+    program, ok := parseSource(displayName(flags.Arg(0)), src)
+    if !ok {
+        return 1
+    }
+
+    if *tailCalls {
+        optimize.MarkTailCalls(program)
+    }
+
+    if *asCFG {
+        if err := cfg.WriteDot(os.Stdout, cfg.Build(program)...); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            return 1
+        }
+        return 0
+    }
+
+    if *asDOT {
+        if err := dot.Write(os.Stdout, program); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            return 1
+        }
+        return 0
+    }
+
+    if !*asJSON {
+        fmt.Println(program.String())
+        return 0
+    }
+
+    data, err := ast.MarshalJSON(program)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+
+    var out bytes.Buffer
+    if err := json.Indent(&out, data, "", "  "); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    out.WriteString("\n")
+    out.WriteTo(os.Stdout)
+
+    return 0
+}
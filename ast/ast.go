@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/UsamaHameed/monkey-interpreter/token"
 )
@@ -21,6 +22,12 @@ type Expression interface {
     expressionNode()
 }
 
+// TypeExpr is a type annotation, such as `int` or `fn(int) -> bool`.
+type TypeExpr interface {
+    Node
+    typeExprNode()
+}
+
 type Program struct {
     Statements []Statement
 }
@@ -147,9 +154,12 @@ func (ie *InfixExpression) String() string {
     return out.String()
 }
 
+// Identifier is a name. Let names and function parameters may carry a
+// declared type in Annotation; it is nil everywhere else.
 type Identifier struct {
     Token token.Token
     Value string
+    Annotation TypeExpr
 }
 
 func (i *Identifier) expressionNode() {}
@@ -157,6 +167,9 @@ func (i *Identifier) TokenLiteral() string {
     return i.Token.Literal
 }
 func (i *Identifier) String() string {
+    if i.Annotation != nil {
+        return i.Value + ": " + i.Annotation.String()
+    }
     return i.Value
 }
 
@@ -186,6 +199,18 @@ func (b *Boolean) String() string {
     return b.Token.Literal
 }
 
+type NullLiteral struct {
+    Token token.Token
+}
+
+func (n *NullLiteral) expressionNode() {}
+func (n *NullLiteral) TokenLiteral() string {
+    return n.Token.Literal
+}
+func (n *NullLiteral) String() string {
+    return n.Token.Literal
+}
+
 type IfExpression struct {
     Token token.Token
     Condition Expression
@@ -200,14 +225,16 @@ func (ie *IfExpression) TokenLiteral() string {
 func (ie *IfExpression) String() string {
     var out bytes.Buffer
 
-    out.WriteString("if")
+    out.WriteString("if (")
     out.WriteString(ie.Condition.String())
-    out.WriteString(" ")
+    out.WriteString(") { ")
     out.WriteString(ie.Consequence.String())
+    out.WriteString(" }")
 
     if ie.Alternative != nil {
-        out.WriteString("else ")
+        out.WriteString(" else { ")
         out.WriteString(ie.Alternative.String())
+        out.WriteString(" }")
     }
 
     return out.String()
@@ -232,10 +259,17 @@ func (bs *BlockStatement) String() string {
     return out.String()
 }
 
+// FunctionLiteral is either `fn(x) { ... }` or the arrow shorthand
+// `x => ...`. ImplicitReturn is set when an arrow function was written
+// with a bare expression instead of a block; the expression is then the
+// only statement of Body. Result is the declared result type of
+// `fn(x) -> T { ... }`, if any.
 type FunctionLiteral struct {
-    Token       token.Token
-    Body        *BlockStatement
-    Parameters  []*Identifier
+    Token           token.Token
+    Body            *BlockStatement
+    Parameters      []*Identifier
+    ImplicitReturn  bool
+    Result          TypeExpr
 }
 
 func (fl *FunctionLiteral) expressionNode() {}
@@ -245,20 +279,40 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 func (fl *FunctionLiteral) String() string {
     var out bytes.Buffer
 
-    out.WriteString("(")
+    params := []string{}
     for _, ident := range fl.Parameters {
-        out.WriteString(ident.String() + ", ")
+        params = append(params, ident.String())
+    }
+
+    if fl.Token.Type == token.ARROW {
+        out.WriteString("(" + strings.Join(params, ", ") + ") => ")
+    } else {
+        out.WriteString(fl.TokenLiteral())
+        out.WriteString("(" + strings.Join(params, ", ") + ") ")
+        if fl.Result != nil {
+            out.WriteString("-> " + fl.Result.String() + " ")
+        }
+    }
+
+    if fl.ImplicitReturn {
+        out.WriteString(fl.Body.String())
+    } else {
+        out.WriteString("{ ")
+        out.WriteString(fl.Body.String())
+        out.WriteString(" }")
     }
-    out.WriteString(")")
-    out.WriteString(fl.Body.String())
 
     return out.String()
 }
 
+// CallExpression is `Function(Arguments)`. IsTail is set by
+// optimize.MarkTailCalls on calls whose value the enclosing function
+// returns; the parser leaves it unset.
 type CallExpression struct {
     Token       token.Token
     Function    Expression
     Arguments   []Expression
+    IsTail      bool
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -268,14 +322,151 @@ func (ce *CallExpression) TokenLiteral() string {
 func (ce *CallExpression) String() string {
     var out bytes.Buffer
 
+    args := []string{}
+    for _, arg := range ce.Arguments {
+        args = append(args, arg.String())
+    }
+
     out.WriteString(ce.Function.String())
     out.WriteString("(")
+    out.WriteString(strings.Join(args, ", "))
+    out.WriteString(")")
 
-    for _, arg := range ce.Arguments {
-        out.WriteString(arg.String())
-    }
+    return out.String()
+}
+
+// PipeExpression is `Left |> Right`. It is kept as its own node so the
+// pipeline form survives formatting; Call gives the equivalent call.
+type PipeExpression struct {
+    Token   token.Token
+    Left    Expression
+    Right   Expression
+}
+
+func (pe *PipeExpression) expressionNode() {}
+func (pe *PipeExpression) TokenLiteral() string {
+    return pe.Token.Literal
+}
+func (pe *PipeExpression) String() string {
+    var out bytes.Buffer
 
+    out.WriteString("(")
+    out.WriteString(pe.Left.String())
+    out.WriteString(" |> ")
+    out.WriteString(pe.Right.String())
     out.WriteString(")")
 
     return out.String()
 }
+
+// Call lowers the pipeline into a call with Left inserted as the first
+// argument: `x |> f(y)` becomes `f(x, y)` and `x |> f` becomes `f(x)`.
+func (pe *PipeExpression) Call() *CallExpression {
+    if call, ok := pe.Right.(*CallExpression); ok {
+        args := append([]Expression{pe.Left}, call.Arguments...)
+        return &CallExpression{Token: call.Token, Function: call.Function, Arguments: args}
+    }
+
+    return &CallExpression{
+        Token:      token.Token{Type: token.LPAREN, Literal: "("},
+        Function:   pe.Right,
+        Arguments:  []Expression{pe.Left},
+    }
+}
+
+// MemberExpression is `Object.Property`, or `Object?.Property` when
+// Optional is set.
+type MemberExpression struct {
+    Token       token.Token
+    Object      Expression
+    Property    *Identifier
+    Optional    bool
+}
+
+func (me *MemberExpression) expressionNode() {}
+func (me *MemberExpression) TokenLiteral() string {
+    return me.Token.Literal
+}
+func (me *MemberExpression) String() string {
+    var out bytes.Buffer
+
+    out.WriteString(me.Object.String())
+    if me.Optional {
+        out.WriteString("?.")
+    } else {
+        out.WriteString(".")
+    }
+    out.WriteString(me.Property.String())
+
+    return out.String()
+}
+
+// Placeholder is a `$Name` hole in a template, to be substituted with
+// another node. It only appears in trees parsed with ParseTemplate.
+type Placeholder struct {
+    Token   token.Token
+    Name    string
+}
+
+func (ph *Placeholder) expressionNode() {}
+func (ph *Placeholder) TokenLiteral() string {
+    return ph.Token.Literal
+}
+func (ph *Placeholder) String() string {
+    return "$" + ph.Name
+}
+
+// NamedType is a type referred to by name, such as `int`.
+type NamedType struct {
+    Token   token.Token
+    Name    string
+}
+
+func (nt *NamedType) typeExprNode() {}
+func (nt *NamedType) TokenLiteral() string {
+    return nt.Token.Literal
+}
+func (nt *NamedType) String() string {
+    return nt.Name
+}
+
+// GenericType applies a named type to type arguments, as in
+// `list<int>`. Token is the `<`.
+type GenericType struct {
+    Token   token.Token
+    Base    *NamedType
+    Args    []TypeExpr
+}
+
+func (gt *GenericType) typeExprNode() {}
+func (gt *GenericType) TokenLiteral() string {
+    return gt.Token.Literal
+}
+func (gt *GenericType) String() string {
+    args := []string{}
+    for _, arg := range gt.Args {
+        args = append(args, arg.String())
+    }
+
+    return gt.Base.String() + "<" + strings.Join(args, ", ") + ">"
+}
+
+// FuncType is the type of a function, `fn(Params) -> Result`.
+type FuncType struct {
+    Token   token.Token
+    Params  []TypeExpr
+    Result  TypeExpr
+}
+
+func (ft *FuncType) typeExprNode() {}
+func (ft *FuncType) TokenLiteral() string {
+    return ft.Token.Literal
+}
+func (ft *FuncType) String() string {
+    params := []string{}
+    for _, param := range ft.Params {
+        params = append(params, param.String())
+    }
+
+    return "fn(" + strings.Join(params, ", ") + ") -> " + ft.Result.String()
+}
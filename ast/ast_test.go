@@ -27,3 +27,29 @@ func TestPrintAst(t *testing.T) {
         t.Errorf("program.String() printed wrong ast, got=%q", program.String())
     }
 }
+
+func TestPipeExpressionCall(t *testing.T) {
+    pipe := &PipeExpression{
+        Token: token.Token{Type: token.PIPE, Literal: "|>"},
+        Left: &Identifier{
+            Token: token.Token{Type: token.IDENT, Literal: "x"},
+            Value: "x",
+        },
+        Right: &Identifier{
+            Token: token.Token{Type: token.IDENT, Literal: "f"},
+            Value: "f",
+        },
+    }
+
+    if pipe.String() != "(x |> f)" {
+        t.Errorf("pipe.String() wrong, got=%q", pipe.String())
+    }
+
+    call := pipe.Call()
+    if call.Function != pipe.Right {
+        t.Errorf("call.Function is not pipe.Right, got=%s", call.Function)
+    }
+    if len(call.Arguments) != 1 || call.Arguments[0] != pipe.Left {
+        t.Errorf("call.Arguments is not [pipe.Left], got=%v", call.Arguments)
+    }
+}
@@ -0,0 +1,213 @@
+package astutil_test
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/astutil"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+func ident(name string) *ast.Identifier {
+    return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+func integer(value int64, literal string) *ast.IntegerLiteral {
+    return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal}, Value: value}
+}
+
+func statement(e ast.Expression) *ast.ExpressionStatement {
+    return &ast.ExpressionStatement{Token: token.Token{Type: token.IDENT, Literal: e.TokenLiteral()}, Expression: e}
+}
+
+func TestApplyReplace(t *testing.T) {
+    program := testutil.Parse(t, "let x = a + b; f(a);")
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        if i, ok := c.Node().(*ast.Identifier); ok && i.Value == "a" {
+            c.Replace(integer(1, "1"))
+        }
+        return true
+    }, nil)
+
+    expected := "let x = (1 + b);f(1)"
+    if program.String() != expected {
+        t.Errorf("expected=%q, got=%q", expected, program.String())
+    }
+}
+
+func TestApplyReplaceRoot(t *testing.T) {
+    program := testutil.Parse(t, "x;")
+    replacement := &ast.Program{}
+
+    result := astutil.Apply(program, func(c *astutil.Cursor) bool {
+        if _, ok := c.Node().(*ast.Program); ok {
+            c.Replace(replacement)
+            return false
+        }
+        return true
+    }, nil)
+
+    if result != replacement {
+        t.Errorf("Apply did not return the replaced root, got=%T", result)
+    }
+}
+
+func TestApplyDeleteStatements(t *testing.T) {
+    program := testutil.Parse(t, "a; b; c; if (x) { d; e; f; }")
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        s, ok := c.Node().(*ast.ExpressionStatement)
+        if !ok {
+            return true
+        }
+        if i, ok := s.Expression.(*ast.Identifier); ok && (i.Value == "b" || i.Value == "e") {
+            c.Delete()
+        }
+        return true
+    }, nil)
+
+    if len(program.Statements) != 3 {
+        t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+    }
+
+    ifExp := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+    if ifExp.Consequence.String() != "df" {
+        t.Errorf("Consequence wrong. expected=%q, got=%q", "df", ifExp.Consequence.String())
+    }
+
+    got := program.Statements[0].String() + program.Statements[1].String()
+    if got != "ac" {
+        t.Errorf("program.Statements wrong. expected=%q, got=%q", "ac", got)
+    }
+}
+
+func TestApplyInsertStatements(t *testing.T) {
+    program := testutil.Parse(t, "a; b;")
+
+    visited := []string{}
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        s, ok := c.Node().(*ast.ExpressionStatement)
+        if !ok {
+            return true
+        }
+        visited = append(visited, s.String())
+        if s.String() == "a" {
+            c.InsertBefore(statement(ident("before")))
+            c.InsertAfter(statement(ident("after")))
+            if c.Index() != 1 {
+                t.Errorf("c.Index() not 1 after InsertBefore. got=%d", c.Index())
+            }
+        }
+        return true
+    }, nil)
+
+    if program.String() != "beforeaafterb" {
+        t.Errorf("expected=%q, got=%q", "beforeaafterb", program.String())
+    }
+
+    // inserted nodes are not walked
+    if len(visited) != 2 || visited[0] != "a" || visited[1] != "b" {
+        t.Errorf("visited wrong statements, got=%v", visited)
+    }
+}
+
+func TestApplyCallArguments(t *testing.T) {
+    program := testutil.Parse(t, "f(a, b, c);")
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        i, ok := c.Node().(*ast.Identifier)
+        if !ok || c.Name() != "Arguments" {
+            return true
+        }
+
+        if _, ok := c.Parent().(*ast.CallExpression); !ok {
+            t.Errorf("c.Parent() is not *ast.CallExpression. got=%T", c.Parent())
+        }
+
+        switch i.Value {
+        case "a":
+            c.InsertAfter(integer(1, "1"))
+        case "b":
+            c.Delete()
+        case "c":
+            c.Replace(integer(2, "2"))
+        }
+        return true
+    }, nil)
+
+    call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+    if len(call.Arguments) != 3 {
+        t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+    }
+
+    expected := []string{"a", "1", "2"}
+    for i, arg := range call.Arguments {
+        if arg.String() != expected[i] {
+            t.Errorf("call.Arguments[%d] wrong. expected=%q, got=%q", i, expected[i], arg.String())
+        }
+    }
+}
+
+func TestApplyFunctionParameters(t *testing.T) {
+    program := testutil.Parse(t, "fn(x, y) { x };")
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        if c.Name() != "Parameters" {
+            return true
+        }
+        if c.Node().(*ast.Identifier).Value == "x" {
+            c.InsertBefore(ident("w"))
+            c.Delete()
+        } else {
+            c.InsertAfter(ident("z"))
+        }
+        return true
+    }, nil)
+
+    fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+    expected := []string{"w", "y", "z"}
+    if len(fn.Parameters) != len(expected) {
+        t.Fatalf("wrong length of parameters. got=%d", len(fn.Parameters))
+    }
+    for i, p := range fn.Parameters {
+        if p.Value != expected[i] {
+            t.Errorf("fn.Parameters[%d] wrong. expected=%q, got=%q", i, expected[i], p.Value)
+        }
+    }
+}
+
+func TestApplyPostOrderAndAbort(t *testing.T) {
+    program := testutil.Parse(t, "a + b; c;")
+
+    order := []string{}
+    astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+        if i, ok := c.Node().(*ast.Identifier); ok {
+            order = append(order, i.Value)
+            return i.Value != "b"
+        }
+        return true
+    })
+
+    if len(order) != 2 || order[0] != "a" || order[1] != "b" {
+        t.Errorf("expected traversal to stop after b, got=%v", order)
+    }
+}
+
+func TestApplyDeleteOutsideSlicePanics(t *testing.T) {
+    program := testutil.Parse(t, "let x = y;")
+
+    defer func() {
+        if r := recover(); r == nil {
+            t.Errorf("expected Delete on a non-slice field to panic")
+        }
+    }()
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        if c.Name() == "Value" {
+            c.Delete()
+        }
+        return true
+    }, nil)
+}
@@ -0,0 +1,240 @@
+// Package build constructs Monkey syntax trees from Go.
+//
+// The constructors fill in the tokens the parser would have produced for
+// the same code, so a built tree prints, formats and compares like a
+// parsed one:
+//
+//    build.Let("x", build.Infix(build.Int(1), "+", build.Ident("y")))
+//
+// Built tokens carry no position. Constructors panic when given names
+// or operators that could not appear in Monkey source, so a generator
+// cannot produce a tree that fails to round-trip through the parser.
+package build
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+var prefixOperators = map[string]bool{"!": true, "-": true}
+
+var infixOperators = map[string]bool{
+    "+": true, "-": true, "*": true, "/": true,
+    "<": true, ">": true, "==": true, "!=": true,
+    "??": true,
+}
+
+func tok(t token.TokenType, literal string) token.Token {
+    return token.Token{Type: t, Literal: literal}
+}
+
+func isLetter(ch byte) bool {
+    return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+}
+
+// Program returns a program of the given statements.
+func Program(statements ...ast.Statement) *ast.Program {
+    if statements == nil {
+        statements = []ast.Statement{}
+    }
+    return &ast.Program{Statements: statements}
+}
+
+// Let returns the statement "let name = value;".
+func Let(name string, value ast.Expression) *ast.LetStatement {
+    return &ast.LetStatement{
+        Token: tok(token.LET, "let"),
+        Name:  Ident(name),
+        Value: value,
+    }
+}
+
+// Return returns the statement "return value;".
+func Return(value ast.Expression) *ast.ReturnStatement {
+    return &ast.ReturnStatement{Token: tok(token.RETURN, "return"), ReturnValue: value}
+}
+
+// Expr returns an expression statement for e.
+func Expr(e ast.Expression) *ast.ExpressionStatement {
+    return &ast.ExpressionStatement{Token: firstToken(e), Expression: e}
+}
+
+// Block returns a block of the given statements.
+func Block(statements ...ast.Statement) *ast.BlockStatement {
+    if statements == nil {
+        statements = []ast.Statement{}
+    }
+    return &ast.BlockStatement{Token: tok(token.LBRACE, "{"), Statements: statements}
+}
+
+// Ident returns an identifier. It panics if name is not a valid,
+// non-keyword identifier.
+func Ident(name string) *ast.Identifier {
+    if name == "" {
+        panic("build: empty identifier")
+    }
+    for i := 0; i < len(name); i++ {
+        if !isLetter(name[i]) {
+            panic(fmt.Sprintf("build: invalid identifier %q", name))
+        }
+    }
+    if token.LookupIdent(name) != token.IDENT {
+        panic(fmt.Sprintf("build: identifier %q is a keyword", name))
+    }
+
+    return &ast.Identifier{Token: tok(token.IDENT, name), Value: name}
+}
+
+// Int returns an integer literal. It panics if v is negative, since
+// Monkey has no negative literals; use Prefix("-", Int(n)) instead.
+func Int(v int64) *ast.IntegerLiteral {
+    if v < 0 {
+        panic(fmt.Sprintf("build: negative integer literal %d", v))
+    }
+    return &ast.IntegerLiteral{Token: tok(token.INT, strconv.FormatInt(v, 10)), Value: v}
+}
+
+// Bool returns true or false.
+func Bool(v bool) *ast.Boolean {
+    if v {
+        return &ast.Boolean{Token: tok(token.TRUE, "true"), Value: true}
+    }
+    return &ast.Boolean{Token: tok(token.FALSE, "false"), Value: false}
+}
+
+// Null returns the null literal.
+func Null() *ast.NullLiteral {
+    return &ast.NullLiteral{Token: tok(token.NULL, "null")}
+}
+
+// Prefix returns "op right". It panics if op is not "!" or "-".
+func Prefix(op string, right ast.Expression) *ast.PrefixExpression {
+    if !prefixOperators[op] {
+        panic(fmt.Sprintf("build: invalid prefix operator %q", op))
+    }
+    return &ast.PrefixExpression{
+        Token:    tok(token.TokenType(op), op),
+        Operator: op,
+        Right:    right,
+    }
+}
+
+// Infix returns "left op right". It panics if op is not a binary
+// operator; use Pipe for "|>".
+func Infix(left ast.Expression, op string, right ast.Expression) *ast.InfixExpression {
+    if !infixOperators[op] {
+        panic(fmt.Sprintf("build: invalid infix operator %q", op))
+    }
+    return &ast.InfixExpression{
+        Token:    tok(token.TokenType(op), op),
+        Left:     left,
+        Operator: op,
+        Right:    right,
+    }
+}
+
+// If returns "if (condition) { ... } else { ... }". alternative may be
+// nil.
+func If(condition ast.Expression, consequence, alternative *ast.BlockStatement) *ast.IfExpression {
+    return &ast.IfExpression{
+        Token:       tok(token.IF, "if"),
+        Condition:   condition,
+        Consequence: consequence,
+        Alternative: alternative,
+    }
+}
+
+func params(names []string) []*ast.Identifier {
+    identifiers := []*ast.Identifier{}
+    for _, name := range names {
+        identifiers = append(identifiers, Ident(name))
+    }
+    return identifiers
+}
+
+// Func returns "fn(params) { body }".
+func Func(names []string, body *ast.BlockStatement) *ast.FunctionLiteral {
+    return &ast.FunctionLiteral{
+        Token:      tok(token.FUNCTION, "fn"),
+        Parameters: params(names),
+        Body:       body,
+    }
+}
+
+// Arrow returns "(params) => body", whose body is a single expression.
+func Arrow(names []string, body ast.Expression) *ast.FunctionLiteral {
+    stmt := Expr(body)
+    return &ast.FunctionLiteral{
+        Token:          tok(token.ARROW, "=>"),
+        Parameters:     params(names),
+        Body:           &ast.BlockStatement{Token: stmt.Token, Statements: []ast.Statement{stmt}},
+        ImplicitReturn: true,
+    }
+}
+
+// Call returns "function(args)".
+func Call(function ast.Expression, args ...ast.Expression) *ast.CallExpression {
+    if args == nil {
+        args = []ast.Expression{}
+    }
+    return &ast.CallExpression{Token: tok(token.LPAREN, "("), Function: function, Arguments: args}
+}
+
+// Pipe returns "left |> right".
+func Pipe(left, right ast.Expression) *ast.PipeExpression {
+    return &ast.PipeExpression{Token: tok(token.PIPE, "|>"), Left: left, Right: right}
+}
+
+// Member returns "object.property".
+func Member(object ast.Expression, property string) *ast.MemberExpression {
+    return &ast.MemberExpression{Token: tok(token.DOT, "."), Object: object, Property: Ident(property)}
+}
+
+// OptionalMember returns "object?.property".
+func OptionalMember(object ast.Expression, property string) *ast.MemberExpression {
+    return &ast.MemberExpression{
+        Token:    tok(token.OPTDOT, "?."),
+        Object:   object,
+        Property: Ident(property),
+        Optional: true,
+    }
+}
+
+// firstToken returns the token e starts with when printed without
+// redundant parentheses.
+func firstToken(e ast.Expression) token.Token {
+    switch e := e.(type) {
+    case *ast.InfixExpression:
+        return firstToken(e.Left)
+    case *ast.PipeExpression:
+        return firstToken(e.Left)
+    case *ast.CallExpression:
+        return firstToken(e.Function)
+    case *ast.MemberExpression:
+        return firstToken(e.Object)
+    case *ast.FunctionLiteral:
+        if e.Token.Type != token.ARROW {
+            return e.Token
+        }
+        if len(e.Parameters) == 1 {
+            return e.Parameters[0].Token
+        }
+        return tok(token.LPAREN, "(")
+    case *ast.Identifier:
+        return e.Token
+    case *ast.IntegerLiteral:
+        return e.Token
+    case *ast.Boolean:
+        return e.Token
+    case *ast.NullLiteral:
+        return e.Token
+    case *ast.PrefixExpression:
+        return e.Token
+    case *ast.IfExpression:
+        return e.Token
+    }
+    return token.Token{}
+}
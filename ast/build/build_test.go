@@ -0,0 +1,107 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/build"
+	"github.com/UsamaHameed/monkey-interpreter/format"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestBuildMatchesParser(t *testing.T) {
+    tests := []struct {
+        built   *ast.Program
+        source  string
+    }{
+        {
+            build.Program(build.Let("x", build.Infix(build.Int(1), "+", build.Ident("y")))),
+            "let x = 1 + y;",
+        },
+        {
+            build.Program(build.Return(build.Prefix("-", build.Prefix("!", build.Bool(false))))),
+            "return -!false;",
+        },
+        {
+            build.Program(build.Expr(build.Infix(build.Ident("a"), "??", build.Null()))),
+            "a ?? null;",
+        },
+        {
+            build.Program(build.Expr(build.If(
+                build.Infix(build.Ident("x"), "<", build.Int(10)),
+                build.Block(build.Expr(build.Ident("x"))),
+                build.Block(build.Expr(build.Int(10))),
+            ))),
+            "if (x < 10) { x } else { 10 }",
+        },
+        {
+            build.Program(build.Let("add", build.Func([]string{"a", "b"}, build.Block(build.Return(build.Infix(build.Ident("a"), "+", build.Ident("b"))))))),
+            "let add = fn(a, b) { return a + b; };",
+        },
+        {
+            build.Program(build.Let("inc", build.Arrow([]string{"x"}, build.Infix(build.Ident("x"), "+", build.Int(1))))),
+            "let inc = x => x + 1;",
+        },
+        {
+            build.Program(build.Expr(build.Arrow(nil, build.Call(build.Ident("f"))))),
+            "() => f();",
+        },
+        {
+            build.Program(build.Expr(build.Pipe(build.Member(build.OptionalMember(build.Ident("user"), "profile"), "name"), build.Ident("upper")))),
+            "user?.profile.name |> upper;",
+        },
+        {
+            build.Program(build.Expr(build.Call(build.Ident("f"), build.Int(1), build.Call(build.Ident("g"))))),
+            "f(1, g());",
+        },
+    }
+
+    for _, tt := range tests {
+        parsed := testutil.Parse(t, tt.source)
+        if !ast.Equal(tt.built, parsed) {
+            t.Errorf("built tree differs from parsed %q: %v", tt.source, ast.Diff(tt.built, parsed))
+        }
+
+        if got := format.String(tt.built); got != format.String(parsed) {
+            t.Errorf("built tree formats as %q, expected %q", got, format.String(parsed))
+        }
+    }
+}
+
+func TestBuildFormatsRoundTrip(t *testing.T) {
+    program := build.Program(
+        build.Let("x", build.Infix(build.Infix(build.Int(1), "+", build.Int(2)), "*", build.Prefix("-", build.Int(3)))),
+        build.Expr(build.Infix(build.Ident("x"), "-", build.Infix(build.Ident("y"), "-", build.Ident("z")))),
+    )
+
+    src := format.String(program)
+    if !ast.Equal(testutil.Parse(t, src), program) {
+        t.Errorf("formatted program %q does not parse back to the built tree", src)
+    }
+}
+
+func TestBuildPanics(t *testing.T) {
+    tests := []struct {
+        name    string
+        fn      func()
+    }{
+        {"keyword identifier", func() { build.Ident("let") }},
+        {"empty identifier", func() { build.Ident("") }},
+        {"invalid identifier", func() { build.Ident("a-b") }},
+        {"negative integer", func() { build.Int(-1) }},
+        {"prefix operator", func() { build.Prefix("+", build.Int(1)) }},
+        {"infix operator", func() { build.Infix(build.Int(1), "|>", build.Int(2)) }},
+        {"member property", func() { build.Member(build.Ident("a"), "1") }},
+    }
+
+    for _, tt := range tests {
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Errorf("%s: expected a panic", tt.name)
+                }
+            }()
+            tt.fn()
+        }()
+    }
+}
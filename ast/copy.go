@@ -0,0 +1,48 @@
+package ast
+
+import (
+	"reflect"
+)
+
+// Copy returns a deep copy of the tree rooted at node, which shares no
+// nodes with the original. Tokens and other values are copied as is.
+func Copy(node Node) Node {
+    if node == nil {
+        return nil
+    }
+    return copyValue(reflect.ValueOf(node)).Interface().(Node)
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+    if isNil(v) {
+        return v
+    }
+
+    switch v.Kind() {
+    case reflect.Ptr:
+        c := reflect.New(v.Type().Elem())
+        c.Elem().Set(copyValue(v.Elem()))
+        return c
+    case reflect.Interface:
+        c := reflect.New(v.Type()).Elem()
+        c.Set(copyValue(v.Elem()))
+        return c
+    case reflect.Slice:
+        c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+        for i := 0; i < v.Len(); i++ {
+            c.Index(i).Set(copyValue(v.Index(i)))
+        }
+        return c
+    case reflect.Struct:
+        c := reflect.New(v.Type()).Elem()
+        c.Set(v)
+        for i := 0; i < v.NumField(); i++ {
+            if v.Type().Field(i).IsExported() {
+                c.Field(i).Set(copyValue(v.Field(i)))
+            }
+        }
+        return c
+    }
+
+    return v
+}
@@ -0,0 +1,36 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestCopy(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+        copied := ast.Copy(program)
+
+        if !ast.Equal(program, copied) {
+            t.Errorf("%q: copy differs: %v", input, ast.Diff(program, copied))
+        }
+
+        original := map[ast.Node]bool{}
+        ast.Inspect(program, func(n ast.Node) bool {
+            original[n] = true
+            return true
+        })
+        ast.Inspect(copied, func(n ast.Node) bool {
+            if n != nil && original[n] {
+                t.Errorf("%q: copy shares %T %s", input, n, n)
+            }
+            return true
+        })
+    }
+
+    if ast.Copy(nil) != nil {
+        t.Errorf("copy of nil should be nil")
+    }
+}
@@ -0,0 +1,146 @@
+// Package dot renders Monkey syntax trees as Graphviz DOT graphs.
+//
+// Every AST node becomes a graph node labeled with its type and, where
+// it has one, its operator or literal value. Edges are labeled with the
+// name of the field that holds the child, indexed for slices, as in
+// "Arguments[1]".
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+)
+
+type edge struct {
+    label   string
+    node    ast.Node
+}
+
+var nodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+// children returns the non-nil children of n, one for each field of its
+// struct that holds a node and one for each node in a slice field. They
+// are in source order when all of them have positions, and in field
+// order otherwise.
+func children(n ast.Node) []edge {
+    edges := []edge{}
+    add := func(label string, child reflect.Value) {
+        if !child.IsNil() {
+            edges = append(edges, edge{label, child.Interface().(ast.Node)})
+        }
+    }
+
+    v := reflect.ValueOf(n).Elem()
+    t := v.Type()
+    for i := 0; i < t.NumField(); i++ {
+        field, f := t.Field(i), v.Field(i)
+
+        switch {
+        case !field.IsExported():
+        case f.Kind() == reflect.Slice && f.Type().Elem().Implements(nodeType):
+            for j := 0; j < f.Len(); j++ {
+                add(fmt.Sprintf("%s[%d]", field.Name, j), f.Index(j))
+            }
+        case f.Type().Implements(nodeType):
+            add(field.Name, f)
+        }
+    }
+
+    for _, e := range edges {
+        if !ast.Pos(e.node).IsValid() {
+            return edges
+        }
+    }
+    sort.SliceStable(edges, func(i, j int) bool {
+        return ast.Pos(edges[i].node).Offset < ast.Pos(edges[j].node).Offset
+    })
+
+    return edges
+}
+
+// label returns the type of n and its operator or literal, if any.
+func label(n ast.Node) string {
+    name := reflect.TypeOf(n).Elem().Name()
+
+    switch n := n.(type) {
+    case *ast.Identifier:
+        return name + "\n" + n.Value
+    case *ast.IntegerLiteral:
+        return name + "\n" + n.Token.Literal
+    case *ast.Boolean:
+        return fmt.Sprintf("%s\n%t", name, n.Value)
+    case *ast.PrefixExpression:
+        return name + "\n" + n.Operator
+    case *ast.InfixExpression:
+        return name + "\n" + n.Operator
+    case *ast.PipeExpression:
+        return name + "\n|>"
+    case *ast.MemberExpression:
+        if n.Optional {
+            return name + "\n?."
+        }
+        return name + "\n."
+    case *ast.Placeholder:
+        return name + "\n$" + n.Name
+    case *ast.NamedType:
+        return name + "\n" + n.Name
+    case *ast.CallExpression:
+        if n.IsTail {
+            return name + "\ntail"
+        }
+    case *ast.FunctionLiteral:
+        if n.ImplicitReturn {
+            return name + "\n=>"
+        }
+    }
+
+    return name
+}
+
+// quote returns s as a DOT string literal.
+func quote(s string) string {
+    r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+    return `"` + r.Replace(s) + `"`
+}
+
+// Write writes the DOT graph of the tree rooted at node to w.
+func Write(w io.Writer, node ast.Node) error {
+    var buf bytes.Buffer
+
+    buf.WriteString("digraph AST {\n")
+    buf.WriteString("    ordering=out;\n")
+    buf.WriteString("    node [shape=box, fontname=\"monospace\"];\n")
+
+    next := 0
+    var visit func(n ast.Node) int
+    visit = func(n ast.Node) int {
+        id := next
+        next++
+        fmt.Fprintf(&buf, "    n%d [label=%s];\n", id, quote(label(n)))
+
+        for _, e := range children(n) {
+            child := visit(e.node)
+            fmt.Fprintf(&buf, "    n%d -> n%d [label=%s];\n", id, child, quote(e.label))
+        }
+        return id
+    }
+    visit(node)
+
+    buf.WriteString("}\n")
+
+    _, err := buf.WriteTo(w)
+    return err
+}
+
+// String returns the DOT graph of the tree rooted at node.
+func String(node ast.Node) string {
+    var buf bytes.Buffer
+    Write(&buf, node)
+    return buf.String()
+}
@@ -0,0 +1,90 @@
+package dot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestWrite(t *testing.T) {
+    program := testutil.Parse(t, "let x = -a + 1;")
+
+    expected := `digraph AST {
+    ordering=out;
+    node [shape=box, fontname="monospace"];
+    n0 [label="Program"];
+    n1 [label="LetStatement"];
+    n2 [label="Identifier\nx"];
+    n1 -> n2 [label="Name"];
+    n3 [label="InfixExpression\n+"];
+    n4 [label="PrefixExpression\n-"];
+    n5 [label="Identifier\na"];
+    n4 -> n5 [label="Right"];
+    n3 -> n4 [label="Left"];
+    n6 [label="IntegerLiteral\n1"];
+    n3 -> n6 [label="Right"];
+    n1 -> n3 [label="Value"];
+    n0 -> n1 [label="Statements[0]"];
+}
+`
+
+    if got := String(program); got != expected {
+        t.Errorf("wrong output.\nexpected=\n%s\ngot=\n%s", expected, got)
+    }
+}
+
+func TestWriteFieldLabels(t *testing.T) {
+    tests := []struct {
+        input   string
+        labels  []string
+    }{
+        {"if (x) { y } else { z }", []string{"Condition", "Consequence", "Alternative"}},
+        {"f(1, 2)", []string{"Function", "Arguments[0]", "Arguments[1]"}},
+        {"fn(a, b) { a }", []string{"Parameters[0]", "Parameters[1]", "Body"}},
+        {"a?.b", []string{"Object", "Property", `MemberExpression\n?.`}},
+        {"x |> f", []string{`PipeExpression\n|>`, "Left", "Right"}},
+        {"x => x", []string{`FunctionLiteral\n=>`}},
+        {"return null;", []string{"ReturnValue", "NullLiteral"}},
+    }
+
+    for _, tt := range tests {
+        got := String(testutil.Parse(t, tt.input))
+        for _, label := range tt.labels {
+            if !strings.Contains(got, `"`+label+`"`) {
+                t.Errorf("output for %q has no label %q:\n%s", tt.input, label, got)
+            }
+        }
+    }
+}
+
+func TestQuote(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {"a", `"a"`},
+        {`a"b`, `"a\"b"`},
+        {`a\b`, `"a\\b"`},
+        {"a\nb", `"a\nb"`},
+    }
+
+    for _, tt := range tests {
+        if got := quote(tt.input); got != tt.expected {
+            t.Errorf("quote(%q) = %s, expected %s", tt.input, got, tt.expected)
+        }
+    }
+}
+
+func TestWriteCorpus(t *testing.T) {
+    for _, input := range corpus.Programs {
+        got := String(testutil.Parse(t, input))
+
+        nodes := strings.Count(got, "[label=") - strings.Count(got, " -> ")
+        edges := strings.Count(got, " -> ")
+        if nodes != edges+1 {
+            t.Errorf("graph for %q is not a tree: %d nodes, %d edges", input, nodes, edges)
+        }
+    }
+}
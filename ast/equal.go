@@ -0,0 +1,196 @@
+package ast
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+var expressionStatementType = reflect.TypeOf(ExpressionStatement{})
+
+// A Difference is a pair of subtrees that differ between two trees.
+// Path locates them from the roots, as in "Statements[2].Value.Right";
+// it is empty when the roots themselves differ. A or B is nil when the
+// subtree is missing on that side.
+type Difference struct {
+    Path    string
+    A       Node
+    B       Node
+}
+
+func (d Difference) String() string {
+    path := d.Path
+    if path == "" {
+        path = "<root>"
+    }
+    return fmt.Sprintf("%s: %s != %s", path, nodeString(d.A), nodeString(d.B))
+}
+
+func nodeString(n Node) string {
+    if n == nil {
+        return "<nil>"
+    }
+    return fmt.Sprintf("%T(%s)", n, n.String())
+}
+
+// Equal reports whether a and b are structurally equal. Positions are
+// ignored, and so is the token of an expression statement, which starts
+// with a parenthesis that is not kept in `(c);` but not in `c;`. All
+// other fields, including the type and literal of tokens, are compared.
+func Equal(a, b Node) bool {
+    d := differ{limit: 1}
+    d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+    return len(d.diffs) == 0
+}
+
+// Diff returns the smallest differing subtrees of a and b, in source
+// order. Two nodes differ as a whole if their types or any of their
+// non-node fields differ; otherwise their children are compared.
+func Diff(a, b Node) []Difference {
+    d := differ{}
+    d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+    return d.diffs
+}
+
+type differ struct {
+    diffs   []Difference
+    limit   int
+}
+
+func (d *differ) done() bool {
+    return d.limit > 0 && len(d.diffs) >= d.limit
+}
+
+func asNode(v reflect.Value) Node {
+    if !v.IsValid() || isNil(v) {
+        return nil
+    }
+    return v.Interface().(Node)
+}
+
+func isNodeValue(v reflect.Value) bool {
+    return v.Type().Implements(nodeInterface) || v.Type() == nodeInterface
+}
+
+func fieldPath(path, name string) string {
+    if path == "" {
+        return name
+    }
+    return path + "." + name
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+    if d.done() {
+        return
+    }
+
+    na, nb := asNode(a), asNode(b)
+    if na == nil || nb == nil {
+        if na != nil || nb != nil {
+            d.diffs = append(d.diffs, Difference{path, na, nb})
+        }
+        return
+    }
+
+    va, vb := reflect.ValueOf(na), reflect.ValueOf(nb)
+    if va.Type() != vb.Type() || !shallowEqual(va.Elem(), vb.Elem()) {
+        d.diffs = append(d.diffs, Difference{path, na, nb})
+        return
+    }
+
+    sa, sb := va.Elem(), vb.Elem()
+    t := sa.Type()
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        fa, fb := sa.Field(i), sb.Field(i)
+
+        switch {
+        case !field.IsExported():
+        case fa.Kind() == reflect.Slice:
+            for j := 0; j < fa.Len() || j < fb.Len(); j++ {
+                var ea, eb reflect.Value
+                if j < fa.Len() {
+                    ea = fa.Index(j)
+                }
+                if j < fb.Len() {
+                    eb = fb.Index(j)
+                }
+                d.diff(fmt.Sprintf("%s[%d]", fieldPath(path, field.Name), j), ea, eb)
+            }
+        case isNodeValue(fa):
+            d.diff(fieldPath(path, field.Name), fa, fb)
+        }
+    }
+}
+
+// shallowEqual compares the fields of two node structs of the same type
+// that are not nodes.
+func shallowEqual(a, b reflect.Value) bool {
+    t := a.Type()
+    for i := 0; i < t.NumField(); i++ {
+        fa, fb := a.Field(i), b.Field(i)
+
+        switch {
+        case !t.Field(i).IsExported():
+        case fa.Kind() == reflect.Slice, isNodeValue(fa):
+        case fa.Type() == tokenType:
+            ta, tb := fa.Interface().(token.Token), fb.Interface().(token.Token)
+            if t != expressionStatementType && (ta.Type != tb.Type || ta.Literal != tb.Literal) {
+                return false
+            }
+        default:
+            if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// Hash returns a structural hash of node, consistent with Equal: equal
+// trees have equal hashes.
+func Hash(node Node) uint64 {
+    h := fnv.New64a()
+    hashValue(h, reflect.ValueOf(node))
+    return h.Sum64()
+}
+
+func hashValue(w io.Writer, v reflect.Value) {
+    if !v.IsValid() || isNil(v) {
+        io.WriteString(w, "nil;")
+        return
+    }
+
+    if v.Type() == tokenType {
+        tok := v.Interface().(token.Token)
+        fmt.Fprintf(w, "%s %q;", tok.Type, tok.Literal)
+        return
+    }
+
+    switch v.Kind() {
+    case reflect.Interface, reflect.Ptr:
+        hashValue(w, v.Elem())
+    case reflect.Slice:
+        fmt.Fprintf(w, "[%d;", v.Len())
+        for i := 0; i < v.Len(); i++ {
+            hashValue(w, v.Index(i))
+        }
+        io.WriteString(w, "];")
+    case reflect.Struct:
+        fmt.Fprintf(w, "%s{", v.Type().Name())
+        for i := 0; i < v.NumField(); i++ {
+            if v.Type() == expressionStatementType && v.Field(i).Type() == tokenType {
+                continue
+            }
+            if v.Type().Field(i).IsExported() {
+                hashValue(w, v.Field(i))
+            }
+        }
+        io.WriteString(w, "};")
+    default:
+        fmt.Fprintf(w, "%#v;", v.Interface())
+    }
+}
@@ -0,0 +1,154 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestEqualIgnoresPositions(t *testing.T) {
+    a := testutil.Parse(t, "let x = fn(a, b) { a + b };")
+    b := testutil.Parse(t, "let   x =\n    fn(a,b) {\n        a+b\n    };")
+
+    if !ast.Equal(a, b) {
+        t.Errorf("expected programs to be equal, diff=%v", ast.Diff(a, b))
+    }
+    if ast.Hash(a) != ast.Hash(b) {
+        t.Errorf("equal programs have different hashes")
+    }
+}
+
+func TestEqualIgnoresStatementTokens(t *testing.T) {
+    // the first token of a statement is part of the statement node, and
+    // parentheses are not kept
+    a := testutil.Parse(t, "if (a) { b }; (c)")
+    b := testutil.Parse(t, "if (a) { b }; c")
+
+    if a.Statements[1].(*ast.ExpressionStatement).Token.Literal != "(" {
+        t.Fatalf("expected the statement to start with (")
+    }
+    if !ast.Equal(a, b) {
+        t.Errorf("expected programs to be equal, diff=%v", ast.Diff(a, b))
+    }
+    if ast.Hash(a) != ast.Hash(b) {
+        t.Errorf("equal programs have different hashes")
+    }
+}
+
+func TestEqualCorpus(t *testing.T) {
+    for _, input := range corpus.Programs {
+        a, b := testutil.Parse(t, input), testutil.Parse(t, input)
+        if !ast.Equal(a, b) {
+            t.Errorf("%q is not equal to itself: %v", input, ast.Diff(a, b))
+        }
+        if ast.Hash(a) != ast.Hash(b) {
+            t.Errorf("%q hashes differently on each parse", input)
+        }
+    }
+}
+
+func TestNotEqual(t *testing.T) {
+    tests := []struct {
+        a   string
+        b   string
+    }{
+        {"x", "y"},
+        {"1", "2"},
+        {"a + b", "a - b"},
+        {"a.b", "a?.b"},
+        {"f(x)", "f(x, y)"},
+        {"x => x", "fn(x) { x }"},
+        {"(a, b) => { a }", "fn(a, b) { a }"},
+        {"if (x) { y }", "if (x) { y } else { z }"},
+        {"let x = 1;", "return 1;"},
+        {"true", "false"},
+    }
+
+    for _, tt := range tests {
+        a, b := testutil.Parse(t, tt.a), testutil.Parse(t, tt.b)
+        if ast.Equal(a, b) {
+            t.Errorf("%q and %q should not be equal", tt.a, tt.b)
+        }
+        if ast.Hash(a) == ast.Hash(b) {
+            t.Errorf("%q and %q have the same hash", tt.a, tt.b)
+        }
+    }
+}
+
+func TestEqualNil(t *testing.T) {
+    if !ast.Equal(nil, nil) {
+        t.Errorf("nil should equal nil")
+    }
+    if ast.Equal(testutil.Parse(t, "x"), nil) {
+        t.Errorf("program should not equal nil")
+    }
+}
+
+func TestDiff(t *testing.T) {
+    tests := []struct {
+        a           string
+        b           string
+        expected    []string
+    }{
+        {"let x = 1;", "let x = 1;", []string{}},
+        {
+            "let a = 1; let b = 2; let c = x + y;",
+            "let a = 1; let b = 2; let c = x + z;",
+            []string{"Statements[2].Value.Right: *ast.Identifier(y) != *ast.Identifier(z)"},
+        },
+        {
+            "f(1, 2)",
+            "g(1, 3)",
+            []string{
+                "Statements[0].Expression.Function: *ast.Identifier(f) != *ast.Identifier(g)",
+                "Statements[0].Expression.Arguments[1]: *ast.IntegerLiteral(2) != *ast.IntegerLiteral(3)",
+            },
+        },
+        {
+            "a + b",
+            "a * b",
+            []string{"Statements[0].Expression: *ast.InfixExpression((a + b)) != *ast.InfixExpression((a * b))"},
+        },
+        {
+            "x; y;",
+            "x;",
+            []string{"Statements[1]: *ast.ExpressionStatement(y) != <nil>"},
+        },
+        {
+            "if (x) { y }",
+            "if (x) { y } else { z }",
+            []string{"Statements[0].Expression.Alternative: <nil> != *ast.BlockStatement(z)"},
+        },
+    }
+
+    for _, tt := range tests {
+        diffs := ast.Diff(testutil.Parse(t, tt.a), testutil.Parse(t, tt.b))
+
+        if len(diffs) != len(tt.expected) {
+            t.Errorf("Diff(%q, %q) returned %d differences, expected %d: %v",
+                tt.a, tt.b, len(diffs), len(tt.expected), diffs)
+            continue
+        }
+
+        for i, d := range diffs {
+            if d.String() != tt.expected[i] {
+                t.Errorf("Diff(%q, %q)[%d] wrong.\nexpected=%q\ngot=     %q",
+                    tt.a, tt.b, i, tt.expected[i], d.String())
+            }
+        }
+    }
+}
+
+func TestDiffRoot(t *testing.T) {
+    a, b := testutil.Parse(t, "x").Statements[0], testutil.Parse(t, "let x = 1;").Statements[0]
+
+    diffs := ast.Diff(a, b)
+    if len(diffs) != 1 || diffs[0].Path != "" {
+        t.Fatalf("expected a single difference at the root, got %v", diffs)
+    }
+    if diffs[0].A != a || diffs[0].B != b {
+        t.Errorf("difference does not hold the roots: %v", diffs[0])
+    }
+}
@@ -0,0 +1,254 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// nodeTypes maps the "type" discriminator used in JSON to node types.
+var nodeTypes = map[string]reflect.Type{}
+
+func init() {
+    for _, n := range []Node{
+        &Program{},
+        &LetStatement{},
+        &ReturnStatement{},
+        &ExpressionStatement{},
+        &PrefixExpression{},
+        &InfixExpression{},
+        &Identifier{},
+        &IntegerLiteral{},
+        &Boolean{},
+        &NullLiteral{},
+        &IfExpression{},
+        &BlockStatement{},
+        &FunctionLiteral{},
+        &CallExpression{},
+        &PipeExpression{},
+        &MemberExpression{},
+        &Placeholder{},
+        &NamedType{},
+        &GenericType{},
+        &FuncType{},
+    } {
+        t := reflect.TypeOf(n).Elem()
+        nodeTypes[t.Name()] = t
+    }
+}
+
+var (
+    nodeInterface = reflect.TypeOf((*Node)(nil)).Elem()
+    tokenType     = reflect.TypeOf(token.Token{})
+)
+
+type jsonPosition struct {
+    Offset int `json:"offset"`
+    Line   int `json:"line"`
+    Column int `json:"column"`
+}
+
+type jsonToken struct {
+    Type    token.TokenType `json:"type"`
+    Literal string          `json:"literal"`
+    Pos     jsonPosition    `json:"pos"`
+}
+
+// MarshalJSON encodes node and its children as JSON. Every node becomes
+// an object with a "type" discriminator naming its Go type, followed by
+// its fields with lower-cased names; tokens are encoded with their type,
+// literal and position.
+func MarshalJSON(node Node) ([]byte, error) {
+    var buf bytes.Buffer
+
+    if err := encodeValue(&buf, reflect.ValueOf(node)); err != nil {
+        return nil, err
+    }
+
+    return buf.Bytes(), nil
+}
+
+func jsonName(field string) string {
+    r, size := utf8.DecodeRuneInString(field)
+    return string(unicode.ToLower(r)) + field[size:]
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+    switch {
+    case v.Type() == tokenType:
+        tok := v.Interface().(token.Token)
+        b, err := json.Marshal(jsonToken{
+            Type:    tok.Type,
+            Literal: tok.Literal,
+            Pos:     jsonPosition{Offset: tok.Pos.Offset, Line: tok.Pos.Line, Column: tok.Pos.Column},
+        })
+        buf.Write(b)
+        return err
+
+    case v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr:
+        if v.IsNil() {
+            buf.WriteString("null")
+            return nil
+        }
+        if v.Kind() == reflect.Interface {
+            return encodeValue(buf, v.Elem())
+        }
+        return encodeNode(buf, v)
+
+    case v.Kind() == reflect.Slice:
+        if v.IsNil() {
+            buf.WriteString("null")
+            return nil
+        }
+        buf.WriteString("[")
+        for i := 0; i < v.Len(); i++ {
+            if i > 0 {
+                buf.WriteString(",")
+            }
+            if err := encodeValue(buf, v.Index(i)); err != nil {
+                return err
+            }
+        }
+        buf.WriteString("]")
+        return nil
+
+    default:
+        b, err := json.Marshal(v.Interface())
+        buf.Write(b)
+        return err
+    }
+}
+
+func encodeNode(buf *bytes.Buffer, v reflect.Value) error {
+    t := v.Type().Elem()
+    if nodeTypes[t.Name()] != t {
+        return fmt.Errorf("ast: cannot marshal node type %s", v.Type())
+    }
+
+    fmt.Fprintf(buf, `{"type":%q`, t.Name())
+
+    s := v.Elem()
+    for i := 0; i < t.NumField(); i++ {
+        fmt.Fprintf(buf, ",%q:", jsonName(t.Field(i).Name))
+        if err := encodeValue(buf, s.Field(i)); err != nil {
+            return err
+        }
+    }
+
+    buf.WriteString("}")
+    return nil
+}
+
+// UnmarshalProgram decodes a program encoded by MarshalJSON back into
+// the same Go structs.
+func UnmarshalProgram(data []byte) (*Program, error) {
+    node, err := UnmarshalNode(data)
+    if err != nil {
+        return nil, err
+    }
+
+    program, ok := node.(*Program)
+    if !ok {
+        return nil, fmt.Errorf("ast: expected Program, got %T", node)
+    }
+
+    return program, nil
+}
+
+// UnmarshalNode decodes any node encoded by MarshalJSON.
+func UnmarshalNode(data []byte) (Node, error) {
+    v := reflect.New(nodeInterface).Elem()
+
+    if err := decodeValue(data, v); err != nil {
+        return nil, err
+    }
+    if v.IsNil() {
+        return nil, nil
+    }
+
+    return v.Interface().(Node), nil
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+    if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+        v.Set(reflect.Zero(v.Type()))
+        return nil
+    }
+
+    switch {
+    case v.Type() == tokenType:
+        var tok jsonToken
+        if err := json.Unmarshal(data, &tok); err != nil {
+            return err
+        }
+        v.Set(reflect.ValueOf(token.Token{
+            Type:    tok.Type,
+            Literal: tok.Literal,
+            Pos:     token.Position{Offset: tok.Pos.Offset, Line: tok.Pos.Line, Column: tok.Pos.Column},
+        }))
+        return nil
+
+    case v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr:
+        node, err := decodeNode(data)
+        if err != nil {
+            return err
+        }
+        if !node.Type().AssignableTo(v.Type()) {
+            return fmt.Errorf("ast: cannot use %s as %s", node.Type(), v.Type())
+        }
+        v.Set(node)
+        return nil
+
+    case v.Kind() == reflect.Slice:
+        var elems []json.RawMessage
+        if err := json.Unmarshal(data, &elems); err != nil {
+            return err
+        }
+        s := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+        for i, e := range elems {
+            if err := decodeValue(e, s.Index(i)); err != nil {
+                return err
+            }
+        }
+        v.Set(s)
+        return nil
+
+    default:
+        return json.Unmarshal(data, v.Addr().Interface())
+    }
+}
+
+func decodeNode(data []byte) (reflect.Value, error) {
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal(data, &fields); err != nil {
+        return reflect.Value{}, err
+    }
+
+    var name string
+    if err := json.Unmarshal(fields["type"], &name); err != nil {
+        return reflect.Value{}, fmt.Errorf("ast: node without type: %s", data)
+    }
+
+    t, ok := nodeTypes[name]
+    if !ok {
+        return reflect.Value{}, fmt.Errorf("ast: unknown node type %q", name)
+    }
+
+    node := reflect.New(t)
+    for i := 0; i < t.NumField(); i++ {
+        raw, ok := fields[jsonName(t.Field(i).Name)]
+        if !ok {
+            continue
+        }
+        if err := decodeValue(raw, node.Elem().Field(i)); err != nil {
+            return reflect.Value{}, fmt.Errorf("ast: %s.%s: %w", name, t.Field(i).Name, err)
+        }
+    }
+
+    return node, nil
+}
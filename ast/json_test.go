@@ -0,0 +1,106 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+
+        data, err := ast.MarshalJSON(program)
+        if err != nil {
+            t.Fatalf("MarshalJSON(%q) returned error: %s", input, err)
+        }
+
+        if !json.Valid(data) {
+            t.Fatalf("MarshalJSON(%q) produced invalid JSON: %s", input, data)
+        }
+
+        decoded, err := ast.UnmarshalProgram(data)
+        if err != nil {
+            t.Fatalf("UnmarshalProgram returned error for %q: %s", input, err)
+        }
+
+        if !reflect.DeepEqual(decoded, program) {
+            t.Errorf("round trip of %q changed the program.\nexpected=%q\ngot=     %q",
+                input, program.String(), decoded.String())
+        }
+    }
+}
+
+func TestJSONEncoding(t *testing.T) {
+    program := testutil.Parse(t, "let x = -y;")
+
+    data, err := ast.MarshalJSON(program.Statements[0])
+    if err != nil {
+        t.Fatalf("MarshalJSON returned error: %s", err)
+    }
+
+    expected := `{"type":"LetStatement",` +
+        `"token":{"type":"LET","literal":"let","pos":{"offset":0,"line":1,"column":1}},` +
+        `"name":{"type":"Identifier","token":{"type":"IDENT","literal":"x","pos":{"offset":4,"line":1,"column":5}},"value":"x","annotation":null},` +
+        `"value":{"type":"PrefixExpression","token":{"type":"-","literal":"-","pos":{"offset":8,"line":1,"column":9}},"operator":"-",` +
+        `"right":{"type":"Identifier","token":{"type":"IDENT","literal":"y","pos":{"offset":9,"line":1,"column":10}},"value":"y","annotation":null}}}`
+
+    if string(data) != expected {
+        t.Errorf("MarshalJSON wrong.\nexpected=%s\ngot=     %s", expected, data)
+    }
+}
+
+func TestJSONNilFields(t *testing.T) {
+    program := &ast.Program{Statements: []ast.Statement{
+        &ast.ReturnStatement{},
+        &ast.ExpressionStatement{Expression: &ast.IfExpression{}},
+    }}
+
+    data, err := ast.MarshalJSON(program)
+    if err != nil {
+        t.Fatalf("MarshalJSON returned error: %s", err)
+    }
+
+    if !strings.Contains(string(data), `"returnValue":null`) {
+        t.Errorf("nil field not encoded as null: %s", data)
+    }
+
+    decoded, err := ast.UnmarshalProgram(data)
+    if err != nil {
+        t.Fatalf("UnmarshalProgram returned error: %s", err)
+    }
+
+    if !reflect.DeepEqual(decoded, program) {
+        t.Errorf("round trip changed the program: %#v", decoded.Statements[0])
+    }
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {`{"type":"Spaceship"}`, `ast: unknown node type "Spaceship"`},
+        {`{"statements":[]}`, "ast: node without type"},
+        {`{"type":"Identifier","value":"x"}`, "ast: expected Program, got *ast.Identifier"},
+        {
+            `{"type":"Program","statements":[{"type":"Identifier","value":"x"}]}`,
+            "ast: Program.Statements: ast: cannot use *ast.Identifier as ast.Statement",
+        },
+    }
+
+    for _, tt := range tests {
+        _, err := ast.UnmarshalProgram([]byte(tt.input))
+        if err == nil {
+            t.Fatalf("expected error for %s, got none", tt.input)
+        }
+        if !strings.HasPrefix(err.Error(), tt.expected) {
+            t.Errorf("expected error %q, got=%q", tt.expected, err.Error())
+        }
+    }
+}
@@ -0,0 +1,61 @@
+package ast
+
+import (
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Pos returns the position of the first token of n that is recorded in
+// the tree. Parentheses are not recorded, so the position of `(a + b)`
+// is that of `a`. It returns an invalid position for an empty program
+// or a node built without positions.
+func Pos(n Node) token.Position {
+    switch n := n.(type) {
+    case *Program:
+        if len(n.Statements) > 0 {
+            return Pos(n.Statements[0])
+        }
+    case *LetStatement:
+        return n.Token.Pos
+    case *ReturnStatement:
+        return n.Token.Pos
+    case *ExpressionStatement:
+        return n.Token.Pos
+    case *BlockStatement:
+        return n.Token.Pos
+    case *Identifier:
+        return n.Token.Pos
+    case *IntegerLiteral:
+        return n.Token.Pos
+    case *Boolean:
+        return n.Token.Pos
+    case *NullLiteral:
+        return n.Token.Pos
+    case *Placeholder:
+        return n.Token.Pos
+    case *PrefixExpression:
+        return n.Token.Pos
+    case *InfixExpression:
+        return Pos(n.Left)
+    case *PipeExpression:
+        return Pos(n.Left)
+    case *CallExpression:
+        return Pos(n.Function)
+    case *MemberExpression:
+        return Pos(n.Object)
+    case *IfExpression:
+        return n.Token.Pos
+    case *FunctionLiteral:
+        if n.Token.Type == token.ARROW && len(n.Parameters) > 0 {
+            return Pos(n.Parameters[0])
+        }
+        return n.Token.Pos
+    case *NamedType:
+        return n.Token.Pos
+    case *GenericType:
+        return Pos(n.Base)
+    case *FuncType:
+        return n.Token.Pos
+    }
+
+    return token.Position{}
+}
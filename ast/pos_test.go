@@ -0,0 +1,42 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestPos(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {"x", "1:1"},
+        {"  let x = 1;", "1:3"},
+        {"\n  a + b", "2:3"},
+        {"f(x).y |> g", "1:1"},
+        {"-x", "1:1"},
+        {"(a, b) => a", "1:2"},
+        {"() => 1", "1:4"},
+        {"fn() { 1 }", "1:1"},
+        {"fn(a: int) -> int { a }", "1:1"},
+        {"", "-"},
+    }
+
+    for _, tt := range tests {
+        program := testutil.Parse(t, tt.input)
+
+        var node ast.Node = program
+        if len(program.Statements) > 0 {
+            node = program.Statements[0]
+            if es, ok := node.(*ast.ExpressionStatement); ok {
+                node = es.Expression
+            }
+        }
+
+        if got := ast.Pos(node).String(); got != tt.expected {
+            t.Errorf("Pos(%q) = %s, expected %s", tt.input, got, tt.expected)
+        }
+    }
+}
@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Fprint prints the tree rooted at node to w, one field per line, in the
+// style of go/ast.Print. Nodes are shown with their Go type, tokens with
+// their type, literal and position. Nil fields are skipped, as are
+// zero-valued flags and strings, so a new field only shows up where it is set.
+func Fprint(w io.Writer, node Node) error {
+    p := &treePrinter{w: bufio.NewWriter(w)}
+    p.print(reflect.ValueOf(node))
+    p.printf("\n")
+    return p.w.Flush()
+}
+
+type treePrinter struct {
+    w       *bufio.Writer
+    indent  int
+}
+
+func (p *treePrinter) printf(format string, args ...interface{}) {
+    fmt.Fprintf(p.w, format, args...)
+}
+
+func (p *treePrinter) newline() {
+    p.printf("\n")
+    for i := 0; i < p.indent; i++ {
+        p.printf(".  ")
+    }
+}
+
+func isNil(v reflect.Value) bool {
+    switch v.Kind() {
+    case reflect.Ptr, reflect.Interface, reflect.Slice:
+        return v.IsNil()
+    }
+    return false
+}
+
+// isZeroScalar reports whether v is a zero value that holds no nodes.
+func isZeroScalar(v reflect.Value) bool {
+    switch v.Kind() {
+    case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Struct:
+        return false
+    }
+    return v.IsZero()
+}
+
+func (p *treePrinter) print(v reflect.Value) {
+    if isNil(v) {
+        p.printf("nil")
+        return
+    }
+
+    if v.Type() == tokenType {
+        tok := v.Interface().(token.Token)
+        p.printf("%s %q @ %s", tok.Type, tok.Literal, tok.Pos)
+        return
+    }
+
+    switch v.Kind() {
+    case reflect.Interface:
+        p.print(v.Elem())
+
+    case reflect.Ptr:
+        p.printf("*")
+        p.print(v.Elem())
+
+    case reflect.Slice:
+        p.printf("%s (len = %d) {", v.Type(), v.Len())
+        if v.Len() > 0 {
+            p.indent++
+            for i := 0; i < v.Len(); i++ {
+                p.newline()
+                p.printf("%d: ", i)
+                p.print(v.Index(i))
+            }
+            p.indent--
+            p.newline()
+        }
+        p.printf("}")
+
+    case reflect.Struct:
+        t := v.Type()
+        p.printf("%s {", t)
+        p.indent++
+        for i := 0; i < t.NumField(); i++ {
+            if !t.Field(i).IsExported() || isNil(v.Field(i)) || isZeroScalar(v.Field(i)) {
+                continue
+            }
+            p.newline()
+            p.printf("%s: ", t.Field(i).Name)
+            p.print(v.Field(i))
+        }
+        p.indent--
+        p.newline()
+        p.printf("}")
+
+    case reflect.String:
+        p.printf("%q", v.String())
+
+    default:
+        p.printf("%v", v.Interface())
+    }
+}
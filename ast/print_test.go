@@ -0,0 +1,77 @@
+package ast_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestFprint(t *testing.T) {
+    program := testutil.Parse(t, "return -x;")
+
+    expected := `*ast.Program {
+.  Statements: []ast.Statement (len = 1) {
+.  .  0: *ast.ReturnStatement {
+.  .  .  Token: RETURN "return" @ 1:1
+.  .  .  ReturnValue: *ast.PrefixExpression {
+.  .  .  .  Token: - "-" @ 1:8
+.  .  .  .  Operator: "-"
+.  .  .  .  Right: *ast.Identifier {
+.  .  .  .  .  Token: IDENT "x" @ 1:9
+.  .  .  .  .  Value: "x"
+.  .  .  .  }
+.  .  .  }
+.  .  }
+.  }
+}
+`
+
+    var buf bytes.Buffer
+    if err := ast.Fprint(&buf, program); err != nil {
+        t.Fatalf("Fprint returned error: %s", err)
+    }
+
+    if buf.String() != expected {
+        t.Errorf("wrong output.\nexpected=\n%s\ngot=\n%s", expected, buf.String())
+    }
+}
+
+func TestFprintSkipsNilFields(t *testing.T) {
+    program := testutil.Parse(t, "if (x) { y }")
+
+    var buf bytes.Buffer
+    ast.Fprint(&buf, program)
+
+    if strings.Contains(buf.String(), "Alternative") {
+        t.Errorf("nil Alternative was printed:\n%s", buf.String())
+    }
+    if !strings.Contains(buf.String(), "Consequence: *ast.BlockStatement {") {
+        t.Errorf("Consequence was not printed:\n%s", buf.String())
+    }
+}
+
+func TestFprintSkipsZeroFlags(t *testing.T) {
+    var buf bytes.Buffer
+    ast.Fprint(&buf, &ast.CallExpression{Function: &ast.Identifier{Value: "f"}})
+    if strings.Contains(buf.String(), "IsTail") {
+        t.Errorf("zero IsTail was printed:\n%s", buf.String())
+    }
+
+    buf.Reset()
+    ast.Fprint(&buf, &ast.CallExpression{Function: &ast.Identifier{Value: "f"}, IsTail: true})
+    if !strings.Contains(buf.String(), "IsTail: true") {
+        t.Errorf("IsTail was not printed:\n%s", buf.String())
+    }
+}
+
+func TestFprintPositionlessNode(t *testing.T) {
+    var buf bytes.Buffer
+    ast.Fprint(&buf, &ast.Identifier{Value: "x"})
+
+    if !strings.Contains(buf.String(), `Token:  "" @ -`) {
+        t.Errorf("unexpected output for a node without a token:\n%s", buf.String())
+    }
+}
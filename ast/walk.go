@@ -0,0 +1,168 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+    Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, visiting the children of
+// every node in the order they appear in the source. It starts by
+// calling v.Visit(node); node must not be nil.
+func Walk(v Visitor, node Node) {
+    if v = v.Visit(node); v == nil {
+        return
+    }
+
+    switch n := node.(type) {
+    case *Program:
+        walkStatements(v, n.Statements)
+
+    case *LetStatement:
+        if n.Name != nil {
+            Walk(v, n.Name)
+        }
+        if n.Value != nil {
+            Walk(v, n.Value)
+        }
+
+    case *ReturnStatement:
+        if n.ReturnValue != nil {
+            Walk(v, n.ReturnValue)
+        }
+
+    case *ExpressionStatement:
+        if n.Expression != nil {
+            Walk(v, n.Expression)
+        }
+
+    case *BlockStatement:
+        walkStatements(v, n.Statements)
+
+    case *Identifier:
+        if n.Annotation != nil {
+            Walk(v, n.Annotation)
+        }
+
+    case *IntegerLiteral, *Boolean, *NullLiteral, *Placeholder, *NamedType:
+        // nothing to do
+
+    case *PrefixExpression:
+        if n.Right != nil {
+            Walk(v, n.Right)
+        }
+
+    case *InfixExpression:
+        if n.Left != nil {
+            Walk(v, n.Left)
+        }
+        if n.Right != nil {
+            Walk(v, n.Right)
+        }
+
+    case *IfExpression:
+        if n.Condition != nil {
+            Walk(v, n.Condition)
+        }
+        if n.Consequence != nil {
+            Walk(v, n.Consequence)
+        }
+        if n.Alternative != nil {
+            Walk(v, n.Alternative)
+        }
+
+    case *FunctionLiteral:
+        for _, p := range n.Parameters {
+            Walk(v, p)
+        }
+        if n.Result != nil {
+            Walk(v, n.Result)
+        }
+        if n.Body != nil {
+            Walk(v, n.Body)
+        }
+
+    case *CallExpression:
+        if n.Function != nil {
+            Walk(v, n.Function)
+        }
+        walkExpressions(v, n.Arguments)
+
+    case *PipeExpression:
+        if n.Left != nil {
+            Walk(v, n.Left)
+        }
+        if n.Right != nil {
+            Walk(v, n.Right)
+        }
+
+    case *MemberExpression:
+        if n.Object != nil {
+            Walk(v, n.Object)
+        }
+        if n.Property != nil {
+            Walk(v, n.Property)
+        }
+
+    case *GenericType:
+        if n.Base != nil {
+            Walk(v, n.Base)
+        }
+        walkTypes(v, n.Args)
+
+    case *FuncType:
+        walkTypes(v, n.Params)
+        if n.Result != nil {
+            Walk(v, n.Result)
+        }
+
+    default:
+        panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+    }
+
+    v.Visit(nil)
+}
+
+func walkStatements(v Visitor, list []Statement) {
+    for _, s := range list {
+        if s != nil {
+            Walk(v, s)
+        }
+    }
+}
+
+func walkExpressions(v Visitor, list []Expression) {
+    for _, e := range list {
+        if e != nil {
+            Walk(v, e)
+        }
+    }
+}
+
+func walkTypes(v Visitor, list []TypeExpr) {
+    for _, t := range list {
+        if t != nil {
+            Walk(v, t)
+        }
+    }
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+    if f(node) {
+        return f
+    }
+    return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+    Walk(inspector(f), node)
+}
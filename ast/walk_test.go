@@ -0,0 +1,155 @@
+package ast_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+// walkInput contains every node type the parser can produce.
+const walkInput = `
+let add = fn(a, b) { return a + b; };
+let double = x => x * 2;
+let pick = (a, b) => { if (a < b) { a } else { b } };
+let name = user?.profile.name ?? null;
+!true == -5;
+add(1, 2) |> double;
+let apply: fn(fn(int) -> int, list<int>) -> int = fn(f: fn(int) -> int, xs) -> int { f(1) };
+`
+
+var nodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+// collectNodes uses reflection to find every node reachable from n
+// through a struct field or slice element, independently of ast.Walk.
+func collectNodes(n ast.Node, found map[ast.Node]bool) {
+    found[n] = true
+
+    v := reflect.ValueOf(n).Elem()
+    for i := 0; i < v.NumField(); i++ {
+        collectValue(v.Field(i), found)
+    }
+}
+
+func collectValue(v reflect.Value, found map[ast.Node]bool) {
+    switch v.Kind() {
+    case reflect.Slice:
+        for i := 0; i < v.Len(); i++ {
+            collectValue(v.Index(i), found)
+        }
+    case reflect.Interface, reflect.Ptr:
+        if v.IsNil() || !v.Type().Implements(nodeType) {
+            return
+        }
+        collectNodes(v.Interface().(ast.Node), found)
+    }
+}
+
+func TestInspectVisitsEveryNode(t *testing.T) {
+    program := testutil.Parse(t, walkInput)
+
+    expected := map[ast.Node]bool{}
+    collectNodes(program, expected)
+
+    visited := map[ast.Node]bool{}
+    types := map[string]bool{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        if n == nil {
+            return false
+        }
+        if visited[n] {
+            t.Errorf("node %T %q visited twice", n, n.String())
+        }
+        visited[n] = true
+        types[reflect.TypeOf(n).Elem().Name()] = true
+        return true
+    })
+
+    for n := range expected {
+        if !visited[n] {
+            t.Errorf("node %T %q not visited", n, n.String())
+        }
+    }
+
+    if len(visited) != len(expected) {
+        t.Errorf("visited %d nodes, expected %d", len(visited), len(expected))
+    }
+
+    for _, name := range []string{
+        "Program", "LetStatement", "ReturnStatement", "ExpressionStatement",
+        "BlockStatement", "Identifier", "IntegerLiteral", "Boolean",
+        "NullLiteral", "PrefixExpression", "InfixExpression", "IfExpression",
+        "FunctionLiteral", "CallExpression", "PipeExpression", "MemberExpression",
+        "NamedType", "GenericType", "FuncType",
+    } {
+        if !types[name] {
+            t.Errorf("walkInput does not contain a %s", name)
+        }
+    }
+}
+
+func TestInspectSourceOrder(t *testing.T) {
+    program := testutil.Parse(t, "let x = f(a, b.c) + -d |> g;")
+
+    identifiers := []string{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        if ident, ok := n.(*ast.Identifier); ok {
+            identifiers = append(identifiers, ident.Value)
+        }
+        return true
+    })
+
+    expected := []string{"x", "f", "a", "b", "c", "d", "g"}
+    if !reflect.DeepEqual(identifiers, expected) {
+        t.Errorf("identifiers visited in wrong order. expected=%v, got=%v",
+            expected, identifiers)
+    }
+}
+
+func TestInspectPrunesSubtrees(t *testing.T) {
+    program := testutil.Parse(t, "let f = fn(x) { x }; y;")
+
+    identifiers := []string{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        if _, ok := n.(*ast.FunctionLiteral); ok {
+            return false
+        }
+        if ident, ok := n.(*ast.Identifier); ok {
+            identifiers = append(identifiers, ident.Value)
+        }
+        return true
+    })
+
+    expected := []string{"f", "y"}
+    if !reflect.DeepEqual(identifiers, expected) {
+        t.Errorf("expected=%v, got=%v", expected, identifiers)
+    }
+}
+
+type depthVisitor struct {
+    depth   int
+    max     *int
+}
+
+func (v depthVisitor) Visit(n ast.Node) ast.Visitor {
+    if n == nil {
+        return nil
+    }
+    if v.depth > *v.max {
+        *v.max = v.depth
+    }
+    return depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+func TestWalkVisitor(t *testing.T) {
+    program := testutil.Parse(t, "1 + 2 * 3;")
+
+    max := 0
+    ast.Walk(depthVisitor{max: &max}, program)
+
+    // Program -> ExpressionStatement -> + -> * -> 3
+    if max != 4 {
+        t.Errorf("expected max depth 4, got=%d", max)
+    }
+}
@@ -0,0 +1,239 @@
+package cfg
+
+import (
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+)
+
+// Build returns the graph of the top level of program, followed by the
+// graphs of its function literals in source order.
+func Build(program *ast.Program) []*Graph {
+    names := map[*ast.FunctionLiteral]string{}
+    functions := []*ast.FunctionLiteral{}
+
+    ast.Inspect(program, func(n ast.Node) bool {
+        switch n := n.(type) {
+        case *ast.LetStatement:
+            if fl, ok := n.Value.(*ast.FunctionLiteral); ok && n.Name != nil {
+                names[fl] = n.Name.Value
+            }
+        case *ast.FunctionLiteral:
+            functions = append(functions, n)
+        }
+        return true
+    })
+
+    graphs := []*Graph{New(program)}
+    for _, fl := range functions {
+        g := New(fl)
+        if name, ok := names[fl]; ok {
+            g.Name = name
+        }
+        graphs = append(graphs, g)
+    }
+
+    return graphs
+}
+
+// New returns the graph of a single body, node being an *ast.Program or
+// an *ast.FunctionLiteral. It returns nil for any other node.
+func New(node ast.Node) *Graph {
+    g := &Graph{Node: node, blocks: map[ast.Node]*Block{}}
+    b := &builder{g: g}
+
+    var statements []ast.Statement
+    switch n := node.(type) {
+    case *ast.Program:
+        g.Name = "program"
+        statements = n.Statements
+    case *ast.FunctionLiteral:
+        g.Name = "fn@" + ast.Pos(n).String()
+        if n.Body != nil {
+            statements = n.Body.Statements
+        }
+    default:
+        return nil
+    }
+
+    // the exit block is created first, so that returns can jump to it,
+    // and moved to the end once the body is done
+    g.Exit = &Block{Kind: Exit}
+    g.Entry = b.newBlock(Entry)
+    b.current = g.Entry
+
+    b.statements(statements)
+    b.jump(b.current, g.Exit)
+
+    g.Exit.Index = len(g.Blocks)
+    g.Blocks = append(g.Blocks, g.Exit)
+
+    markLive(g.Entry)
+
+    return g
+}
+
+func markLive(b *Block) {
+    if b.Live {
+        return
+    }
+    b.Live = true
+    for _, s := range b.Succs {
+        markLive(s)
+    }
+}
+
+type builder struct {
+    g       *Graph
+    // current is the block being filled, or nil right after a return
+    current *Block
+}
+
+func (b *builder) newBlock(kind Kind) *Block {
+    block := &Block{Index: len(b.g.Blocks), Kind: kind}
+    b.g.Blocks = append(b.g.Blocks, block)
+    return block
+}
+
+// block returns the block being filled, starting an unreachable one if
+// the previous statement returned.
+func (b *builder) block() *Block {
+    if b.current == nil {
+        b.current = b.newBlock(Unreachable)
+    }
+    return b.current
+}
+
+// jump adds an edge from one block to another; there is none from nil.
+func (b *builder) jump(from, to *Block) {
+    if from == nil {
+        return
+    }
+    from.Succs = append(from.Succs, to)
+    to.Preds = append(to.Preds, from)
+}
+
+// add lists n in the current block. A nil n, as found in trees with
+// errors, is left out, and so is a condition that is itself the call
+// site just listed.
+func (b *builder) add(n ast.Node) {
+    block := b.block()
+    if n == nil {
+        return
+    }
+    if k := len(block.Nodes); k > 0 && block.Nodes[k-1] == n {
+        return
+    }
+    block.Nodes = append(block.Nodes, n)
+    b.g.blocks[n] = block
+}
+
+func (b *builder) statements(list []ast.Statement) {
+    for _, s := range list {
+        b.statement(s)
+    }
+}
+
+func (b *builder) statement(s ast.Statement) {
+    switch s := s.(type) {
+    case *ast.LetStatement:
+        b.expression(s.Value)
+        b.add(s)
+
+    case *ast.ReturnStatement:
+        b.expression(s.ReturnValue)
+        b.add(s)
+        b.current.Return = s
+        b.jump(b.current, b.g.Exit)
+        b.current = nil
+
+    case *ast.ExpressionStatement:
+        b.expression(s.Expression)
+        b.add(s)
+    }
+}
+
+// expression lists the call sites and conditions of e. Identifiers,
+// literals and function literals need no evaluation beyond their own.
+func (b *builder) expression(e ast.Expression) {
+    switch e := e.(type) {
+    case *ast.PrefixExpression:
+        b.expression(e.Right)
+
+    case *ast.InfixExpression:
+        b.expression(e.Left)
+        if e.Operator != "??" {
+            b.expression(e.Right)
+            return
+        }
+
+        // the right operand is only evaluated if the left one is null
+        b.add(e.Left)
+        cond := b.block()
+        right := b.newBlock(NullishRight)
+        b.jump(cond, right)
+        b.current = right
+        b.expression(e.Right)
+
+        done := b.newBlock(NullishDone)
+        b.jump(cond, done)
+        b.jump(b.current, done)
+        b.current = done
+
+    case *ast.IfExpression:
+        b.expression(e.Condition)
+        b.add(e.Condition)
+        cond := b.block()
+
+        then := b.newBlock(IfThen)
+        b.jump(cond, then)
+        b.current = then
+        if e.Consequence != nil {
+            b.statements(e.Consequence.Statements)
+        }
+        thenEnd := b.current
+
+        var elseEnd *Block
+        if e.Alternative != nil {
+            els := b.newBlock(IfElse)
+            b.jump(cond, els)
+            b.current = els
+            b.statements(e.Alternative.Statements)
+            elseEnd = b.current
+        }
+
+        done := b.newBlock(IfDone)
+        b.jump(thenEnd, done)
+        if e.Alternative != nil {
+            b.jump(elseEnd, done)
+        } else {
+            b.jump(cond, done)
+        }
+        b.current = done
+
+    case *ast.CallExpression:
+        b.expression(e.Function)
+        for _, a := range e.Arguments {
+            b.expression(a)
+        }
+        b.add(e)
+
+    case *ast.PipeExpression:
+        // the call on the right is not a call of its own: the pipe is
+        // the call site
+        b.expression(e.Left)
+        if call, ok := e.Right.(*ast.CallExpression); ok {
+            b.expression(call.Function)
+            for _, a := range call.Arguments {
+                b.expression(a)
+            }
+        } else {
+            b.expression(e.Right)
+        }
+        b.add(e)
+
+    case *ast.MemberExpression:
+        b.expression(e.Object)
+
+    case *ast.BlockStatement:
+        b.statements(e.Statements)
+    }
+}
@@ -0,0 +1,228 @@
+// Package cfg builds control-flow graphs of Monkey programs.
+//
+// A graph covers one body: the top level of a program, or the body of a
+// function literal. Function literals nested in a body are values there
+// and get graphs of their own. The blocks of a graph list the nodes they
+// evaluate, in order:
+//
+//   - call sites, *ast.CallExpression and *ast.PipeExpression, once
+//     their operands have been evaluated;
+//   - the condition of an if expression and the left operand of `??`,
+//     which end their block;
+//   - statements, once they complete.
+//
+// A statement whose value contains an if expression thus completes in a
+// later block than it started in. Return statements jump to the exit
+// block, and whatever follows them starts a block without predecessors.
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+)
+
+// Kind tells why a block was started.
+type Kind int
+
+const (
+    Entry Kind = iota
+    Exit
+    IfThen
+    IfElse
+    IfDone
+    // NullishRight evaluates the right operand of `??`, and NullishDone
+    // continues after it.
+    NullishRight
+    NullishDone
+    // Unreachable follows a return statement.
+    Unreachable
+)
+
+var kindNames = [...]string{
+    Entry:          "entry",
+    Exit:           "exit",
+    IfThen:         "if.then",
+    IfElse:         "if.else",
+    IfDone:         "if.done",
+    NullishRight:   "nullish.right",
+    NullishDone:    "nullish.done",
+    Unreachable:    "unreachable",
+}
+
+func (k Kind) String() string {
+    if k < 0 || int(k) >= len(kindNames) {
+        return "Kind(?)"
+    }
+    return kindNames[k]
+}
+
+// A Block is a basic block: its nodes are evaluated in order, and
+// control then passes to one of its successors.
+type Block struct {
+    Index   int
+    Kind    Kind
+    Nodes   []ast.Node
+    Succs   []*Block
+    Preds   []*Block
+    // Live is set if the block can be reached from the entry block.
+    Live    bool
+    // Return is the statement the block ends with, if it returns.
+    Return  *ast.ReturnStatement
+}
+
+func (b *Block) String() string {
+    return fmt.Sprintf("b%d %s", b.Index, b.Kind)
+}
+
+// Graph is the control-flow graph of one body.
+type Graph struct {
+    // Node is the *ast.Program or *ast.FunctionLiteral of the body.
+    Node    ast.Node
+    // Name is "program" for the top level, the name a function is
+    // bound to by let, or "fn@line:col".
+    Name    string
+    // Blocks holds the blocks in the order they were started. The entry
+    // block comes first and the exit block last.
+    Blocks  []*Block
+    Entry   *Block
+    Exit    *Block
+
+    blocks  map[ast.Node]*Block
+}
+
+// BlockOf returns the block that lists n, or nil if the graph does not
+// list n.
+func (g *Graph) BlockOf(n ast.Node) *Block {
+    return g.blocks[n]
+}
+
+// Reachable reports whether the point where n completes can be reached
+// from the start of the body. It is false for nodes the graph does not
+// list.
+func (g *Graph) Reachable(n ast.Node) bool {
+    b := g.blocks[n]
+    return b != nil && b.Live
+}
+
+// Returns reports whether every path through the body ends in a return
+// statement, rather than falling off its end.
+func (g *Graph) Returns() bool {
+    for _, b := range g.Exit.Preds {
+        if b.Live && b.Return == nil {
+            return false
+        }
+    }
+    return true
+}
+
+// Calls returns the call sites of the body in evaluation order, block
+// by block.
+func (g *Graph) Calls() []ast.Expression {
+    calls := []ast.Expression{}
+    for _, b := range g.Blocks {
+        for _, n := range b.Nodes {
+            switch n := n.(type) {
+            case *ast.CallExpression:
+                calls = append(calls, n)
+            case *ast.PipeExpression:
+                calls = append(calls, n)
+            }
+        }
+    }
+    return calls
+}
+
+// String lists the blocks of g with their successors and nodes, one
+// node per line.
+func (g *Graph) String() string {
+    var out bytes.Buffer
+
+    for _, b := range g.Blocks {
+        out.WriteString(b.String())
+        if !b.Live {
+            out.WriteString(" (dead)")
+        }
+        if len(b.Succs) > 0 {
+            out.WriteString(" ->")
+            for _, s := range b.Succs {
+                fmt.Fprintf(&out, " b%d", s.Index)
+            }
+        }
+        out.WriteString("\n")
+
+        for _, n := range b.Nodes {
+            out.WriteString("    " + describe(n) + "\n")
+        }
+    }
+
+    return out.String()
+}
+
+const maxDescription = 40
+
+// describe returns the position of n and its source, shortened to fit
+// a graph label.
+func describe(n ast.Node) string {
+    s := n.String()
+    if utf8.RuneCountInString(s) > maxDescription {
+        s = string([]rune(s)[:maxDescription-3]) + "..."
+    }
+    return ast.Pos(n).String() + " " + s
+}
+
+// quote returns s as a DOT string literal; `\l` sequences in s are kept
+// as line breaks.
+func quote(s string) string {
+    r := strings.NewReplacer(`\l`, `\l`, `\`, `\\`, `"`, `\"`, "\n", `\n`)
+    return `"` + r.Replace(s) + `"`
+}
+
+// WriteDot writes the graphs to w as one Graphviz DOT graph, with a
+// cluster for each. Dead blocks are drawn dashed.
+func WriteDot(w io.Writer, graphs ...*Graph) error {
+    var buf bytes.Buffer
+
+    buf.WriteString("digraph CFG {\n")
+    buf.WriteString("    node [shape=box, fontname=\"monospace\"];\n")
+
+    for i, g := range graphs {
+        fmt.Fprintf(&buf, "    subgraph cluster_%d {\n", i)
+        fmt.Fprintf(&buf, "        label=%s;\n", quote(g.Name))
+
+        for _, b := range g.Blocks {
+            label := b.String() + `\l`
+            for _, n := range b.Nodes {
+                label += describe(n) + `\l`
+            }
+            style := ""
+            if !b.Live {
+                style = ", style=dashed"
+            }
+            fmt.Fprintf(&buf, "        g%db%d [label=%s%s];\n", i, b.Index, quote(label), style)
+        }
+        for _, b := range g.Blocks {
+            for _, s := range b.Succs {
+                fmt.Fprintf(&buf, "        g%db%d -> g%db%d;\n", i, b.Index, i, s.Index)
+            }
+        }
+
+        buf.WriteString("    }\n")
+    }
+
+    buf.WriteString("}\n")
+
+    _, err := buf.WriteTo(w)
+    return err
+}
+
+// Dot returns the DOT graph written by WriteDot.
+func Dot(graphs ...*Graph) string {
+    var buf bytes.Buffer
+    WriteDot(&buf, graphs...)
+    return buf.String()
+}
@@ -0,0 +1,254 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+// function returns the graph of the first function literal in input.
+func function(t *testing.T, input string) *Graph {
+    t.Helper()
+    graphs := Build(testutil.Parse(t, input))
+    if len(graphs) < 2 {
+        t.Fatalf("%q has no function literal", input)
+    }
+    return graphs[1]
+}
+
+func TestBlocks(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {
+            "fn() {}",
+            `b0 entry -> b1
+b1 exit
+`,
+        },
+        {
+            "fn(x) { let y = f(x); g(y) }",
+            `b0 entry -> b1
+    1:17 f(x)
+    1:9 let y = f(x);
+    1:23 g(y)
+    1:23 g(y)
+b1 exit
+`,
+        },
+        {
+            "fn(x) { if (x) { a() } else { b() } }",
+            `b0 entry -> b1 b2
+    1:13 x
+b1 if.then -> b3
+    1:18 a()
+    1:18 a()
+b2 if.else -> b3
+    1:31 b()
+    1:31 b()
+b3 if.done -> b4
+    1:9 if (x) { a() } else { b() }
+b4 exit
+`,
+        },
+        {
+            "fn(x) { if (f(x)) { return 1; } 2 }",
+            `b0 entry -> b1 b2
+    1:13 f(x)
+b1 if.then -> b3
+    1:21 return 1;
+b2 if.done -> b3
+    1:9 if (f(x)) { return 1; }
+    1:33 2
+b3 exit
+`,
+        },
+        {
+            "fn(x) { return x; f(x) }",
+            `b0 entry -> b2
+    1:9 return x;
+b1 unreachable (dead) -> b2
+    1:19 f(x)
+    1:19 f(x)
+b2 exit
+`,
+        },
+        {
+            "fn(x) { let y = x ?? f(1); y }",
+            `b0 entry -> b1 b2
+    1:17 x
+b1 nullish.right -> b2
+    1:22 f(1)
+b2 nullish.done -> b3
+    1:9 let y = (x ?? f(1));
+    1:28 y
+b3 exit
+`,
+        },
+        {
+            "fn(x) { x |> f(1) |> g }",
+            `b0 entry -> b1
+    1:9 (x |> f(1))
+    1:9 ((x |> f(1)) |> g)
+    1:9 ((x |> f(1)) |> g)
+b1 exit
+`,
+        },
+    }
+
+    for _, tt := range tests {
+        g := function(t, tt.input)
+        if got := g.String(); got != tt.expected {
+            t.Errorf("%q: wrong graph.\nexpected=\n%s\ngot=\n%s", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestReturns(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    bool
+    }{
+        {"fn() {}", false},
+        {"fn() { 1 }", false},
+        {"fn() { return 1; }", true},
+        {"fn(x) { if (x) { return 1; } }", false},
+        {"fn(x) { if (x) { return 1; } else { return 2; } }", true},
+        {"fn(x) { if (x) { return 1; } return 2; }", true},
+        {"fn(x) { if (x) { return 1; } else { 2 } }", false},
+        {"fn(x) { let y = if (x) { return 1; } else { return 2; }; y }", true},
+        {"fn(x) { x ?? f(if (x) { return 1; } else { 2 }) }", false},
+    }
+
+    for _, tt := range tests {
+        g := function(t, tt.input)
+        if got := g.Returns(); got != tt.expected {
+            t.Errorf("%q: Returns() = %t, expected %t\n%s", tt.input, got, tt.expected, g)
+        }
+    }
+}
+
+func TestReachable(t *testing.T) {
+    program := testutil.Parse(t, `let f = fn(x) {
+    if (x) { return 1; } else { return 2; }
+    g(x);
+};
+h(f);`)
+    graphs := Build(program)
+    if len(graphs) != 2 {
+        t.Fatalf("expected 2 graphs, got=%d", len(graphs))
+    }
+    top, f := graphs[0], graphs[1]
+
+    if top.Name != "program" || f.Name != "f" {
+        t.Errorf("wrong names %q and %q", top.Name, f.Name)
+    }
+
+    body := f.Node.(*ast.FunctionLiteral).Body.Statements
+    // both branches return, so the if expression never completes
+    if f.Reachable(body[0]) {
+        t.Errorf("the if statement should be unreachable")
+    }
+    if f.Reachable(body[1]) {
+        t.Errorf("g(x) should be unreachable")
+    }
+    call := body[1].(*ast.ExpressionStatement).Expression
+    if f.Reachable(call) || f.BlockOf(call) == nil {
+        t.Errorf("the call g(x) should be listed in a dead block")
+    }
+
+    for _, s := range program.Statements {
+        if !top.Reachable(s) {
+            t.Errorf("%s should be reachable", s)
+        }
+    }
+    if top.Reachable(call) {
+        t.Errorf("the top level should not list nodes of f")
+    }
+}
+
+func TestCalls(t *testing.T) {
+    g := function(t, "fn(x) { let y = f(g(x)); if (y) { h() } x |> k(1) }")
+
+    calls := []string{}
+    for _, c := range g.Calls() {
+        calls = append(calls, c.String())
+    }
+
+    expected := "g(x) f(g(x)) h() (x |> k(1))"
+    if strings.Join(calls, " ") != expected {
+        t.Errorf("wrong calls. expected=%q, got=%q", expected, strings.Join(calls, " "))
+    }
+}
+
+func TestBuildNestedFunctions(t *testing.T) {
+    graphs := Build(testutil.Parse(t, "let curry = fn(a) { fn(b) { a + b } }; (x => x)(1);"))
+
+    names := []string{}
+    for _, g := range graphs {
+        names = append(names, g.Name)
+    }
+
+    expected := "program curry fn@1:21 fn@1:41"
+    if strings.Join(names, " ") != expected {
+        t.Errorf("wrong graphs. expected=%q, got=%q", expected, strings.Join(names, " "))
+    }
+
+    if len(graphs[1].Calls()) != 0 {
+        t.Errorf("curry should not list the calls of the function it returns")
+    }
+}
+
+func TestWriteDot(t *testing.T) {
+    g := function(t, "fn(x) { return x; 1 }")
+
+    expected := `digraph CFG {
+    node [shape=box, fontname="monospace"];
+    subgraph cluster_0 {
+        label="fn@1:1";
+        g0b0 [label="b0 entry\l1:9 return x;\l"];
+        g0b1 [label="b1 unreachable\l1:19 1\l", style=dashed];
+        g0b2 [label="b2 exit\l"];
+        g0b0 -> g0b2;
+        g0b1 -> g0b2;
+    }
+}
+`
+
+    if got := Dot(g); got != expected {
+        t.Errorf("wrong output.\nexpected=\n%s\ngot=\n%s", expected, got)
+    }
+}
+
+func TestCorpus(t *testing.T) {
+    for _, input := range corpus.Programs {
+        for _, g := range Build(testutil.Parse(t, input)) {
+            if g.Blocks[0] != g.Entry || g.Blocks[len(g.Blocks)-1] != g.Exit {
+                t.Errorf("%q: %s: entry and exit are not first and last", input, g.Name)
+            }
+            if !g.Entry.Live {
+                t.Errorf("%q: %s: entry is not live", input, g.Name)
+            }
+
+            for i, b := range g.Blocks {
+                if b.Index != i {
+                    t.Errorf("%q: %s: block %d has index %d", input, g.Name, i, b.Index)
+                }
+                for _, s := range b.Succs {
+                    if b.Live && !s.Live {
+                        t.Errorf("%q: %s: successor %s of live %s is dead", input, g.Name, s, b)
+                    }
+                }
+                for _, n := range b.Nodes {
+                    if g.BlockOf(n) != b {
+                        t.Errorf("%q: %s: BlockOf(%s) is not %s", input, g.Name, n, b)
+                    }
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,163 @@
+// Package closure finds the variables each function literal captures
+// from enclosing functions.
+//
+// Monkey bindings never change once initialized, so a closure can copy
+// the values it captures, except for bindings that may not have been
+// initialized yet when the function literal is evaluated: a function
+// that refers to itself, or to a let binding further down, has to share
+// the variable with its scope instead. Bindings at the top level of the
+// program are globals and builtins are provided by the host; neither is
+// captured.
+package closure
+
+import (
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/resolve"
+)
+
+// A Capture is a free variable of a function literal.
+type Capture struct {
+    Object  *resolve.Object
+    // ByRef is set if the binding may be initialized only after the
+    // function literal is evaluated, so the closure has to refer to the
+    // variable. Otherwise the capture is read-only and can be copied.
+    ByRef   bool
+}
+
+func (c *Capture) String() string {
+    if c.ByRef {
+        return c.Object.Name + " (by reference)"
+    }
+    return c.Object.Name
+}
+
+// Func describes a function literal.
+type Func struct {
+    Lit     *ast.FunctionLiteral
+    // Parent is the innermost function literal enclosing Lit, or nil.
+    Parent  *Func
+    // Depth is the number of function literals enclosing Lit; it is 0
+    // for a function at the top level.
+    Depth   int
+    // Free lists the captured variables in order of first use.
+    Free    []*Capture
+}
+
+// Capture returns the capture of obj, or nil if f does not capture it.
+func (f *Func) Capture(obj *resolve.Object) *Capture {
+    for _, c := range f.Free {
+        if c.Object == obj {
+            return c
+        }
+    }
+    return nil
+}
+
+// Info holds the result of the analysis.
+type Info struct {
+    Funcs map[*ast.FunctionLiteral]*Func
+}
+
+// Analyze finds the captures of every function literal in program,
+// using the bindings found by resolve.Resolve.
+func Analyze(program *ast.Program, info *resolve.Info) *Info {
+    a := &analyzer{
+        resolve:     info,
+        globals:     info.Scopes[program],
+        result:      &Info{Funcs: map[*ast.FunctionLiteral]*Func{}},
+        initialized: map[*resolve.Object]bool{},
+    }
+    ast.Walk(a, program)
+
+    return a.result
+}
+
+// analyzer walks the program in evaluation order, tracking which let
+// bindings have been initialized.
+type analyzer struct {
+    resolve     *resolve.Info
+    globals     *resolve.Scope
+    result      *Info
+
+    initialized map[*resolve.Object]bool
+    // nodes being visited, and the function literals among them
+    stack       []ast.Node
+    funcs       []*Func
+}
+
+func (a *analyzer) Visit(n ast.Node) ast.Visitor {
+    if n == nil {
+        top := a.stack[len(a.stack)-1]
+        a.stack = a.stack[:len(a.stack)-1]
+
+        switch top := top.(type) {
+        case *ast.LetStatement:
+            if obj := a.resolve.Defs[top.Name]; obj != nil {
+                a.initialized[obj] = true
+            }
+        case *ast.FunctionLiteral:
+            a.funcs = a.funcs[:len(a.funcs)-1]
+        }
+        return nil
+    }
+
+    a.stack = append(a.stack, n)
+    if fl, ok := n.(*ast.FunctionLiteral); ok {
+        a.funcs = append(a.funcs, a.function(fl))
+    }
+
+    return a
+}
+
+// function collects the captures of fl, which is being evaluated. A
+// variable fl shares with its parent is captured the way the parent
+// captures it, since fl gets it from the parent's closure.
+func (a *analyzer) function(fl *ast.FunctionLiteral) *Func {
+    f := &Func{Lit: fl, Depth: len(a.funcs), Free: []*Capture{}}
+    if len(a.funcs) > 0 {
+        f.Parent = a.funcs[len(a.funcs)-1]
+    }
+    a.result.Funcs[fl] = f
+
+    scope := a.resolve.Scopes[fl]
+    seen := map[*resolve.Object]bool{}
+
+    ast.Inspect(fl, func(n ast.Node) bool {
+        id, ok := n.(*ast.Identifier)
+        if !ok {
+            return true
+        }
+        obj := a.resolve.Uses[id]
+        if obj == nil || seen[obj] || !a.captured(obj, scope) {
+            return true
+        }
+        seen[obj] = true
+
+        c := &Capture{Object: obj, ByRef: obj.Kind == resolve.Let && !a.initialized[obj]}
+        if f.Parent != nil {
+            if outer := f.Parent.Capture(obj); outer != nil {
+                c.ByRef = outer.ByRef
+            }
+        }
+        f.Free = append(f.Free, c)
+
+        return true
+    })
+
+    return f
+}
+
+// captured reports whether obj is a local binding declared outside the
+// function whose scope is scope.
+func (a *analyzer) captured(obj *resolve.Object, scope *resolve.Scope) bool {
+    if obj.Kind == resolve.Builtin || obj.Scope == a.globals {
+        return false
+    }
+
+    for s := obj.Scope; s != nil; s = s.Parent {
+        if s == scope {
+            return false
+        }
+    }
+    return true
+}
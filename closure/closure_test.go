@@ -0,0 +1,180 @@
+package closure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+	"github.com/UsamaHameed/monkey-interpreter/resolve"
+)
+
+// describe lists the function literals of input in source order as
+// "depth:captures", with captures separated by commas.
+func describe(t *testing.T, input string, builtins ...string) []string {
+    t.Helper()
+    program := testutil.Parse(t, input)
+    info, errs := resolve.Resolve(program, builtins...)
+    if len(errs) != 0 {
+        t.Fatalf("resolve errors for %q: %v", input, errs)
+    }
+    result := Analyze(program, info)
+
+    funcs := []string{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        if fl, ok := n.(*ast.FunctionLiteral); ok {
+            f := result.Funcs[fl]
+            free := []string{}
+            for _, c := range f.Free {
+                free = append(free, c.String())
+            }
+            funcs = append(funcs, string(rune('0'+f.Depth))+":"+strings.Join(free, ", "))
+        }
+        return true
+    })
+    return funcs
+}
+
+func TestCurried(t *testing.T) {
+    got := describe(t, "fn(a) { fn(b) { fn(c) { a + b + c } } }")
+    expected := []string{"0:", "1:a", "2:a, b"}
+
+    if strings.Join(got, " | ") != strings.Join(expected, " | ") {
+        t.Errorf("wrong captures.\nexpected=%q\ngot=     %q", expected, got)
+    }
+}
+
+func TestCaptures(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        // globals and builtins are not captured
+        {"let g = 1; let f = fn(x) { x + g + len(x) };", []string{"0:"}},
+        {"fn(x) { x }", []string{"0:"}},
+        // captures are listed once, in order of first use
+        {"fn(a, b) { fn() { b + a + b } }", []string{"0:", "1:b, a"}},
+        // a variable used only by a nested function passes through
+        {"fn(a) { fn() { fn() { a } } }", []string{"0:", "1:a", "2:a"}},
+        // lets in the enclosing function and its blocks
+        {"fn() { let x = 1; if (x) { let y = 2; fn() { x + y } } }", []string{"0:", "1:x, y"}},
+        // the parameters and lets of the function itself are not free
+        {"fn(a) { fn(b) { let c = b; fn() { c } } }", []string{"0:", "1:", "2:c"}},
+        // shadowing
+        {"fn(a) { fn(a) { a } }", []string{"0:", "1:"}},
+        {"fn(a) { let f = fn() { a }; let a = 2; fn() { a } }", []string{"0:", "1:a", "1:a"}},
+        // arrow functions
+        {"fn(xs, n) { map(xs, x => x * n) }", []string{"0:", "1:n"}},
+    }
+
+    for _, tt := range tests {
+        got := describe(t, tt.input, "len", "map")
+        if strings.Join(got, " | ") != strings.Join(tt.expected, " | ") {
+            t.Errorf("%q: wrong captures.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestByReference(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        // a recursive local function refers to itself before its let
+        // statement completes
+        {
+            "fn() { let loop = fn(n) { if (n > 0) { loop(n - 1) } }; loop }",
+            []string{"0:", "1:loop (by reference)"},
+        },
+        // mutual recursion: even is created before odd exists, odd after
+        // even does
+        {
+            "fn() { let even = fn(n) { odd(n) }; let odd = fn(n) { even(n) }; even }",
+            []string{"0:", "1:odd (by reference)", "1:even"},
+        },
+        // a function nested in one that captures by reference inherits
+        // the status, one created in a function evaluated later does not
+        {
+            "fn() { let f = fn() { fn() { f } }; let g = fn() { fn() { f } }; g }",
+            []string{"0:", "1:f (by reference)", "2:f (by reference)", "1:f", "2:f"},
+        },
+        // the value of an initialized let is only read
+        {
+            "fn() { let x = 1; let f = fn() { x }; f }",
+            []string{"0:", "1:x"},
+        },
+    }
+
+    for _, tt := range tests {
+        got := describe(t, tt.input)
+        if strings.Join(got, " | ") != strings.Join(tt.expected, " | ") {
+            t.Errorf("%q: wrong captures.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestParents(t *testing.T) {
+    program := testutil.Parse(t, "fn(a) { fn(b) { a } }")
+    info, _ := resolve.Resolve(program)
+    result := Analyze(program, info)
+
+    outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+    inner := outer.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+    if result.Funcs[outer].Parent != nil {
+        t.Errorf("outer function has a parent")
+    }
+    if result.Funcs[inner].Parent != result.Funcs[outer] {
+        t.Errorf("inner function's parent is not the outer function")
+    }
+
+    a := info.Defs[outer.Parameters[0]]
+    if c := result.Funcs[inner].Capture(a); c == nil || c.ByRef {
+        t.Errorf("inner function should capture a read-only, got=%v", c)
+    }
+    if result.Funcs[outer].Capture(a) != nil {
+        t.Errorf("outer function should not capture its own parameter")
+    }
+}
+
+func TestCorpus(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+        info, _ := resolve.Resolve(program)
+        result := Analyze(program, info)
+
+        ast.Inspect(program, func(n ast.Node) bool {
+            fl, ok := n.(*ast.FunctionLiteral)
+            if !ok {
+                return true
+            }
+            f := result.Funcs[fl]
+            if f == nil {
+                t.Errorf("%q: no result for %s", input, fl)
+                return true
+            }
+            // everything captured by a function is either declared in
+            // its parent or captured by the parent in turn
+            for _, c := range f.Free {
+                if f.Parent == nil {
+                    t.Errorf("%q: top-level function %s captures %s", input, fl, c)
+                    continue
+                }
+                if f.Parent.Capture(c.Object) == nil && !declaredIn(info, c.Object, f.Parent.Lit) {
+                    t.Errorf("%q: %s captures %s, which its parent does not have", input, fl, c)
+                }
+            }
+            return true
+        })
+    }
+}
+
+func declaredIn(info *resolve.Info, obj *resolve.Object, fl *ast.FunctionLiteral) bool {
+    for s := obj.Scope; s != nil; s = s.Parent {
+        if s == info.Scopes[fl] {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+type edit struct {
+    op      byte // ' ', '-' or '+'
+    line    string
+}
+
+// diff returns a unified diff of old and new, or nil if they are equal.
+func diff(oldName string, old []byte, newName string, new []byte) []byte {
+    if bytes.Equal(old, new) {
+        return nil
+    }
+
+    edits := lineEdits(splitLines(old), splitLines(new))
+
+    var out bytes.Buffer
+    fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+    // oldLine and newLine are the 1-based line numbers of edits[i]
+    oldLine, newLine := 1, 1
+    for i := 0; i < len(edits); {
+        if edits[i].op == ' ' {
+            oldLine++
+            newLine++
+            i++
+            continue
+        }
+
+        // extend the hunk until there are more than 2*context
+        // unchanged lines between two changes
+        start := i - context
+        if start < 0 {
+            start = 0
+        }
+        end := i
+        for unchanged := 0; end < len(edits) && unchanged <= 2*context; end++ {
+            if edits[end].op == ' ' {
+                unchanged++
+            } else {
+                unchanged = 0
+            }
+        }
+        for end > i && edits[end-1].op == ' ' && trailing(edits[:end]) > context {
+            end--
+        }
+
+        hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+        oldCount, newCount := 0, 0
+        for _, e := range edits[start:end] {
+            if e.op != '+' {
+                oldCount++
+            }
+            if e.op != '-' {
+                newCount++
+            }
+        }
+
+        fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount))
+        for _, e := range edits[start:end] {
+            out.WriteByte(e.op)
+            out.WriteString(e.line)
+            if !strings.HasSuffix(e.line, "\n") {
+                out.WriteString("\n\\ No newline at end of file\n")
+            }
+        }
+
+        for _, e := range edits[i:end] {
+            if e.op != '+' {
+                oldLine++
+            }
+            if e.op != '-' {
+                newLine++
+            }
+        }
+        i = end
+    }
+
+    return out.Bytes()
+}
+
+// trailing counts the unchanged lines at the end of edits.
+func trailing(edits []edit) int {
+    n := 0
+    for i := len(edits) - 1; i >= 0 && edits[i].op == ' '; i-- {
+        n++
+    }
+    return n
+}
+
+func hunkRange(start, count int) string {
+    if count == 0 {
+        start--
+    }
+    if count == 1 {
+        return fmt.Sprintf("%d", start)
+    }
+    return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(b []byte) []string {
+    lines := strings.SplitAfter(string(b), "\n")
+    if lines[len(lines)-1] == "" {
+        lines = lines[:len(lines)-1]
+    }
+    return lines
+}
+
+// lineEdits computes a shortest edit script turning a into b using the
+// longest common subsequence of their lines.
+func lineEdits(a, b []string) []edit {
+    lcs := make([][]int, len(a)+1)
+    for i := range lcs {
+        lcs[i] = make([]int, len(b)+1)
+    }
+    for i := len(a) - 1; i >= 0; i-- {
+        for j := len(b) - 1; j >= 0; j-- {
+            if a[i] == b[j] {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else if lcs[i+1][j] >= lcs[i][j+1] {
+                lcs[i][j] = lcs[i+1][j]
+            } else {
+                lcs[i][j] = lcs[i][j+1]
+            }
+        }
+    }
+
+    edits := []edit{}
+    i, j := 0, 0
+    for i < len(a) && j < len(b) {
+        switch {
+        case a[i] == b[j]:
+            edits = append(edits, edit{' ', a[i]})
+            i++
+            j++
+        case lcs[i+1][j] >= lcs[i][j+1]:
+            edits = append(edits, edit{'-', a[i]})
+            i++
+        default:
+            edits = append(edits, edit{'+', b[j]})
+            j++
+        }
+    }
+    for ; i < len(a); i++ {
+        edits = append(edits, edit{'-', a[i]})
+    }
+    for ; j < len(b); j++ {
+        edits = append(edits, edit{'+', b[j]})
+    }
+
+    return edits
+}
@@ -0,0 +1,170 @@
+// Monkeyfmt formats Monkey programs.
+//
+// Without an explicit path, it processes the standard input. Given a
+// file, it operates on that file; given a directory, it operates on all
+// .mk files in that directory, recursively. By default, monkeyfmt
+// prints the reformatted sources to standard output.
+//
+// Usage:
+//
+//	monkeyfmt [flags] [path ...]
+//
+// The flags are:
+//
+//	-d
+//		Do not print reformatted sources to standard output.
+//		If a file's formatting is different than monkeyfmt's, print diffs
+//		to standard output.
+//	-l
+//		Do not print reformatted sources to standard output.
+//		If a file's formatting is different from monkeyfmt's, print its name
+//		to standard output.
+//	-w
+//		Do not print reformatted sources to standard output.
+//		If a file's formatting is different from monkeyfmt's, overwrite it
+//		with monkeyfmt's version.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/format"
+)
+
+// formatter holds the settings of one run and whether it failed.
+type formatter struct {
+    list    bool
+    write   bool
+    doDiff  bool
+
+    stdout      io.Writer
+    stderr      io.Writer
+    exitCode    int
+}
+
+func (f *formatter) report(err error) {
+    fmt.Fprintln(f.stderr, err)
+    f.exitCode = 2
+}
+
+func isMonkeyFile(f fs.DirEntry) bool {
+    name := f.Name()
+    return !f.IsDir() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".mk")
+}
+
+func (f *formatter) processFile(filename string, in io.Reader, stdin bool) error {
+    src, err := io.ReadAll(in)
+    if err != nil {
+        return err
+    }
+
+    res, err := format.Source(src)
+    if err != nil {
+        return fmt.Errorf("%s: %w", filename, err)
+    }
+
+    if !bytes.Equal(src, res) {
+        if f.list {
+            fmt.Fprintln(f.stdout, filename)
+        }
+        if f.write {
+            if stdin {
+                return fmt.Errorf("can't use -w on stdin")
+            }
+            info, err := os.Stat(filename)
+            if err != nil {
+                return err
+            }
+            if err := os.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+                return err
+            }
+        }
+        if f.doDiff {
+            f.stdout.Write(diff("orig/"+filename, src, filename, res))
+        }
+    }
+
+    if !f.list && !f.write && !f.doDiff {
+        _, err = f.stdout.Write(res)
+    }
+
+    return err
+}
+
+func (f *formatter) walkDir(path string) {
+    filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            f.report(err)
+            return nil
+        }
+        if isMonkeyFile(d) {
+            if err := f.processPath(path); err != nil {
+                f.report(err)
+            }
+        }
+        return nil
+    })
+}
+
+func (f *formatter) processPath(path string) error {
+    file, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    return f.processFile(path, file, false)
+}
+
+// run runs monkeyfmt with the given arguments, not including the
+// program name, and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+    f := &formatter{stdout: stdout, stderr: stderr}
+
+    flags := flag.NewFlagSet("monkeyfmt", flag.ContinueOnError)
+    flags.SetOutput(stderr)
+    flags.BoolVar(&f.list, "l", false, "list files whose formatting differs from monkeyfmt's")
+    flags.BoolVar(&f.write, "w", false, "write result to (source) file instead of stdout")
+    flags.BoolVar(&f.doDiff, "d", false, "display diffs instead of rewriting files")
+    flags.Usage = func() {
+        fmt.Fprintf(stderr, "usage: monkeyfmt [flags] [path ...]\n")
+        flags.PrintDefaults()
+    }
+
+    if err := flags.Parse(args); err != nil {
+        return 2
+    }
+
+    if flags.NArg() == 0 {
+        if err := f.processFile("<standard input>", stdin, true); err != nil {
+            f.report(err)
+        }
+        return f.exitCode
+    }
+
+    for _, path := range flags.Args() {
+        switch info, err := os.Stat(path); {
+        case err != nil:
+            f.report(err)
+        case info.IsDir():
+            f.walkDir(path)
+        default:
+            if err := f.processPath(path); err != nil {
+                f.report(err)
+            }
+        }
+    }
+
+    return f.exitCode
+}
+
+func main() {
+    os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
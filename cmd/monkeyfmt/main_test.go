@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+    unformatted = "let x=1\n"
+    formatted   = "let x = 1;\n"
+)
+
+// runFmt runs monkeyfmt and returns its exit status and output.
+func runFmt(t *testing.T, stdin string, args ...string) (int, string, string) {
+    t.Helper()
+    var stdout, stderr bytes.Buffer
+    code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+    return code, stdout.String(), stderr.String()
+}
+
+// tree writes files, given as name and content pairs, under a new
+// temporary directory and returns it.
+func tree(t *testing.T, files ...string) string {
+    t.Helper()
+    dir := t.TempDir()
+    for i := 0; i < len(files); i += 2 {
+        name := filepath.Join(dir, files[i])
+        if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(name, []byte(files[i+1]), 0640); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return dir
+}
+
+func readFile(t *testing.T, name string) string {
+    t.Helper()
+    data, err := os.ReadFile(name)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(data)
+}
+
+func TestStdin(t *testing.T) {
+    code, stdout, stderr := runFmt(t, unformatted)
+    if code != 0 || stdout != formatted || stderr != "" {
+        t.Errorf("wrong result: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+    }
+
+    code, stdout, stderr = runFmt(t, "let = 1;")
+    if code != 2 || stdout != "" || !strings.HasPrefix(stderr, "<standard input>: format: ") {
+        t.Errorf("wrong result for a parse error: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+    }
+
+    code, _, stderr = runFmt(t, unformatted, "-w")
+    if code != 2 || stderr != "can't use -w on stdin\n" {
+        t.Errorf("wrong result for -w on stdin: code=%d stderr=%q", code, stderr)
+    }
+
+    // a formatted input is not an error, even with -w
+    if code, _, _ := runFmt(t, formatted, "-w"); code != 0 {
+        t.Errorf("-w on formatted stdin exited with %d", code)
+    }
+}
+
+func TestList(t *testing.T) {
+    dir := tree(t,
+        "a.mk", unformatted,
+        "b.mk", formatted,
+        "sub/c.mk", unformatted,
+        "sub/.hidden.mk", unformatted,
+        "sub/notes.txt", unformatted,
+    )
+
+    code, stdout, stderr := runFmt(t, "", "-l", dir)
+
+    expected := filepath.Join(dir, "a.mk") + "\n" + filepath.Join(dir, "sub", "c.mk") + "\n"
+    if code != 0 || stdout != expected || stderr != "" {
+        t.Errorf("wrong result: code=%d stdout=%q stderr=%q\nexpected stdout=%q", code, stdout, stderr, expected)
+    }
+
+    // listing leaves the files alone
+    if got := readFile(t, filepath.Join(dir, "a.mk")); got != unformatted {
+        t.Errorf("-l changed a.mk to %q", got)
+    }
+}
+
+func TestWrite(t *testing.T) {
+    dir := tree(t,
+        "a.mk", unformatted,
+        "b.mk", formatted,
+        "bad.mk", "let = 1;",
+        "sub/c.mk", unformatted,
+    )
+
+    code, stdout, stderr := runFmt(t, "", "-w", dir)
+    if code != 2 || stdout != "" {
+        t.Errorf("wrong result: code=%d stdout=%q", code, stdout)
+    }
+    if !strings.HasPrefix(stderr, filepath.Join(dir, "bad.mk")+": format: ") {
+        t.Errorf("parse error not reported: %q", stderr)
+    }
+
+    for name, expected := range map[string]string{
+        "a.mk":     formatted,
+        "b.mk":     formatted,
+        "bad.mk":   "let = 1;",
+        "sub/c.mk": formatted,
+    } {
+        if got := readFile(t, filepath.Join(dir, name)); got != expected {
+            t.Errorf("%s: expected=%q, got=%q", name, expected, got)
+        }
+    }
+
+    info, err := os.Stat(filepath.Join(dir, "a.mk"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.Mode().Perm() != 0640 {
+        t.Errorf("-w changed the mode of a.mk to %v", info.Mode().Perm())
+    }
+}
+
+func TestDiffFlag(t *testing.T) {
+    dir := tree(t, "a.mk", "let x=1\nx\n", "b.mk", formatted)
+    a := filepath.Join(dir, "a.mk")
+
+    code, stdout, stderr := runFmt(t, "", "-d", a, filepath.Join(dir, "b.mk"))
+
+    expected := "--- orig/" + a + "\n+++ " + a + "\n" +
+        "@@ -1,2 +1,2 @@\n" +
+        "-let x=1\n" +
+        "-x\n" +
+        "+let x = 1;\n" +
+        "+x;\n"
+    if code != 0 || stdout != expected || stderr != "" {
+        t.Errorf("wrong result: code=%d stderr=%q\nexpected=%q\ngot=     %q", code, stderr, expected, stdout)
+    }
+    if got := readFile(t, a); got != "let x=1\nx\n" {
+        t.Errorf("-d changed a.mk to %q", got)
+    }
+}
+
+func TestErrors(t *testing.T) {
+    dir := tree(t, "a.mk", unformatted)
+    missing := filepath.Join(dir, "missing.mk")
+
+    code, stdout, stderr := runFmt(t, "", "-l", missing, filepath.Join(dir, "a.mk"))
+    if code != 2 {
+        t.Errorf("expected exit status 2, got %d", code)
+    }
+    if !strings.Contains(stderr, "missing.mk") {
+        t.Errorf("missing file not reported: %q", stderr)
+    }
+    // the other files are still processed
+    if stdout != filepath.Join(dir, "a.mk")+"\n" {
+        t.Errorf("wrong output %q", stdout)
+    }
+
+    if code, _, _ := runFmt(t, "", "-x"); code != 2 {
+        t.Errorf("unknown flag exited with %d", code)
+    }
+}
+
+func TestDiff(t *testing.T) {
+    // lines returns n distinct lines, some of them changed
+    lines := func(n int, changed map[int]string) string {
+        var b strings.Builder
+        for i := 1; i <= n; i++ {
+            if s, ok := changed[i]; ok {
+                b.WriteString(s + "\n")
+            } else {
+                fmt.Fprintf(&b, "l%d\n", i)
+            }
+        }
+        return b.String()
+    }
+
+    tests := []struct {
+        old         string
+        new         string
+        expected    string
+    }{
+        {"a\n", "a\n", ""},
+        {
+            "a\nb\nc\n", "a\nB\nc\n",
+            "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+        },
+        {
+            "a\n", "a\nb\n",
+            "@@ -1 +1,2 @@\n a\n+b\n",
+        },
+        {
+            "", "a\n",
+            "@@ -0,0 +1 @@\n+a\n",
+        },
+        {
+            "a", "a\n",
+            "@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+        },
+        // changes 6 unchanged lines apart share a hunk
+        {
+            lines(12, nil), lines(12, map[int]string{2: "x", 9: "y"}),
+            "@@ -1,12 +1,12 @@\n l1\n-l2\n+x\n l3\n l4\n l5\n l6\n l7\n l8\n-l9\n+y\n l10\n l11\n l12\n",
+        },
+        // changes further apart get hunks of their own
+        {
+            lines(20, nil), lines(20, map[int]string{2: "x", 18: "y"}),
+            "@@ -1,5 +1,5 @@\n l1\n-l2\n+x\n l3\n l4\n l5\n" +
+            "@@ -15,6 +15,6 @@\n l15\n l16\n l17\n-l18\n+y\n l19\n l20\n",
+        },
+    }
+
+    for _, tt := range tests {
+        got := string(diff("old", []byte(tt.old), "new", []byte(tt.new)))
+        expected := tt.expected
+        if expected != "" {
+            expected = "--- old\n+++ new\n" + expected
+        }
+        if got != expected {
+            t.Errorf("diff(%q, %q) wrong.\nexpected=%q\ngot=     %q", tt.old, tt.new, expected, got)
+        }
+    }
+}
@@ -0,0 +1,289 @@
+package cst
+
+import (
+	"strconv"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// ToAST projects the tree onto the types in package ast. The result is
+// the same tree, token positions included, that parser.ParseProgram
+// builds for the same source. Error nodes are left out.
+func (t *Tree) ToAST() *ast.Program {
+    return t.Root().ToAST().(*ast.Program)
+}
+
+// ToAST projects n onto the corresponding ast.Node. Parenthesized
+// expressions become their contents, and nodes without an ast
+// counterpart, such as Error nodes and argument lists, become nil.
+func (n *Node) ToAST() ast.Node {
+    switch n.Kind() {
+    case SourceFile:
+        return &ast.Program{Statements: n.statements()}
+
+    case LetStatement:
+        s := &ast.LetStatement{Token: n.token(token.LET)}
+        for _, c := range n.Nodes() {
+            if c.Kind() == Identifier && s.Name == nil {
+                s.Name = c.ToAST().(*ast.Identifier)
+            } else {
+                s.Value = c.expression()
+            }
+        }
+        return s
+
+    case ReturnStatement:
+        return &ast.ReturnStatement{Token: n.token(token.RETURN), ReturnValue: n.firstExpression()}
+
+    case ExpressionStatement:
+        s := &ast.ExpressionStatement{}
+        if tok := n.FirstToken(); tok != nil {
+            s.Token = tok.Token()
+        }
+        s.Expression = n.firstExpression()
+        return s
+
+    case Block:
+        return &ast.BlockStatement{Token: n.token(token.LBRACE), Statements: n.statements()}
+
+    case Identifier:
+        tok := n.FirstToken().Token()
+        ident := &ast.Identifier{Token: tok, Value: tok.Literal}
+        for _, c := range n.Nodes() {
+            if c.Kind() == TypeAnnotation {
+                ident.Annotation = c.firstType()
+            }
+        }
+        return ident
+
+    case IntegerLiteral:
+        tok := n.FirstToken().Token()
+        value, err := strconv.ParseInt(tok.Literal, 0, 64)
+        if err != nil {
+            return nil
+        }
+        return &ast.IntegerLiteral{Token: tok, Value: value}
+
+    case BooleanLiteral:
+        tok := n.FirstToken().Token()
+        return &ast.Boolean{Token: tok, Value: tok.Type == token.TRUE}
+
+    case NullLiteral:
+        return &ast.NullLiteral{Token: n.FirstToken().Token()}
+
+    case PrefixExpression:
+        tok := n.FirstToken().Token()
+        return &ast.PrefixExpression{Token: tok, Operator: tok.Literal, Right: n.firstExpression()}
+
+    case InfixExpression:
+        operands := n.Nodes()
+        tok := n.operator()
+        e := &ast.InfixExpression{Token: tok, Operator: tok.Literal, Left: operands[0].expression()}
+        if len(operands) > 1 {
+            e.Right = operands[1].expression()
+        }
+        return e
+
+    case PipeExpression:
+        operands := n.Nodes()
+        e := &ast.PipeExpression{Token: n.operator(), Left: operands[0].expression()}
+        if len(operands) > 1 {
+            e.Right = operands[1].expression()
+        }
+        return e
+
+    case ParenExpression:
+        if e := n.firstExpression(); e != nil {
+            return e
+        }
+        return nil
+
+    case IfExpression:
+        e := &ast.IfExpression{Token: n.token(token.IF), Condition: n.firstExpression()}
+        for _, c := range n.Nodes() {
+            if c.Kind() != Block {
+                continue
+            }
+            if e.Consequence == nil {
+                e.Consequence = c.ToAST().(*ast.BlockStatement)
+            } else {
+                e.Alternative = c.ToAST().(*ast.BlockStatement)
+            }
+        }
+        return e
+
+    case FunctionLiteral:
+        fn := &ast.FunctionLiteral{Token: n.token(token.FUNCTION)}
+        for _, c := range n.Nodes() {
+            switch c.Kind() {
+            case ParameterList:
+                fn.Parameters = c.parameters()
+            case ResultType:
+                fn.Result = c.firstType()
+            case Block:
+                fn.Body = c.ToAST().(*ast.BlockStatement)
+            }
+        }
+        return fn
+
+    case ArrowFunction:
+        fn := &ast.FunctionLiteral{Token: n.token(token.ARROW), Parameters: []*ast.Identifier{}}
+        nodes := n.Nodes()
+        if len(nodes) == 0 {
+            return fn
+        }
+
+        switch nodes[0].Kind() {
+        case Identifier:
+            fn.Parameters = append(fn.Parameters, nodes[0].ToAST().(*ast.Identifier))
+        case ParameterList:
+            fn.Parameters = nodes[0].parameters()
+        }
+
+        if len(nodes) < 2 {
+            return fn
+        }
+        body := nodes[len(nodes)-1]
+        if body.Kind() == Block {
+            fn.Body = body.ToAST().(*ast.BlockStatement)
+            return fn
+        }
+
+        tok := body.FirstToken().Token()
+        fn.Body = &ast.BlockStatement{
+            Token:      tok,
+            Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: body.expression()}},
+        }
+        fn.ImplicitReturn = true
+        return fn
+
+    case CallExpression:
+        nodes := n.Nodes()
+        call := &ast.CallExpression{Function: nodes[0].expression()}
+        args := nodes[len(nodes)-1]
+        call.Token = args.token(token.LPAREN)
+        call.Arguments = []ast.Expression{}
+        for _, a := range args.Nodes() {
+            if e := a.expression(); e != nil {
+                call.Arguments = append(call.Arguments, e)
+            }
+        }
+        return call
+
+    case MemberExpression:
+        nodes := n.Nodes()
+        tok := n.operator()
+        e := &ast.MemberExpression{Token: tok, Object: nodes[0].expression(), Optional: tok.Type == token.OPTDOT}
+        if len(nodes) > 1 {
+            e.Property = nodes[1].ToAST().(*ast.Identifier)
+        }
+        return e
+
+    case NamedType:
+        tok := n.FirstToken().Token()
+        return &ast.NamedType{Token: tok, Name: tok.Literal}
+
+    case GenericType:
+        nodes := n.Nodes()
+        t := &ast.GenericType{Base: nodes[0].ToAST().(*ast.NamedType), Args: []ast.TypeExpr{}}
+        if len(nodes) > 1 {
+            t.Token = nodes[1].token(token.LT)
+            t.Args = nodes[1].types()
+        }
+        return t
+
+    case FuncType:
+        t := &ast.FuncType{Token: n.token(token.FUNCTION), Params: []ast.TypeExpr{}}
+        for _, c := range n.Nodes() {
+            if c.Kind() == TypeList {
+                t.Params = c.types()
+            } else if e, ok := c.ToAST().(ast.TypeExpr); ok {
+                t.Result = e
+            }
+        }
+        return t
+    }
+
+    return nil
+}
+
+// token returns the first direct child token of type t.
+func (n *Node) token(t token.TokenType) token.Token {
+    for _, c := range n.Children() {
+        if c, ok := c.(*Token); ok && c.Type() == t {
+            return c.Token()
+        }
+    }
+    return token.Token{}
+}
+
+// operator returns the first direct child token of n.
+func (n *Node) operator() token.Token {
+    for _, c := range n.Children() {
+        if c, ok := c.(*Token); ok {
+            return c.Token()
+        }
+    }
+    return token.Token{}
+}
+
+func (n *Node) expression() ast.Expression {
+    // BlockStatement implements ast.Expression, but blocks only ever
+    // appear as bodies and branches
+    if n.Kind() == Block {
+        return nil
+    }
+    if e, ok := n.ToAST().(ast.Expression); ok {
+        return e
+    }
+    return nil
+}
+
+func (n *Node) firstExpression() ast.Expression {
+    for _, c := range n.Nodes() {
+        if e := c.expression(); e != nil {
+            return e
+        }
+    }
+    return nil
+}
+
+func (n *Node) statements() []ast.Statement {
+    statements := []ast.Statement{}
+    for _, c := range n.Nodes() {
+        if s, ok := c.ToAST().(ast.Statement); ok {
+            statements = append(statements, s)
+        }
+    }
+    return statements
+}
+
+func (n *Node) parameters() []*ast.Identifier {
+    params := []*ast.Identifier{}
+    for _, c := range n.Nodes() {
+        if c.Kind() == Identifier {
+            params = append(params, c.ToAST().(*ast.Identifier))
+        }
+    }
+    return params
+}
+
+func (n *Node) firstType() ast.TypeExpr {
+    for _, c := range n.Nodes() {
+        if t, ok := c.ToAST().(ast.TypeExpr); ok {
+            return t
+        }
+    }
+    return nil
+}
+
+func (n *Node) types() []ast.TypeExpr {
+    types := []ast.TypeExpr{}
+    for _, c := range n.Nodes() {
+        if t, ok := c.ToAST().(ast.TypeExpr); ok {
+            types = append(types, t)
+        }
+    }
+    return types
+}
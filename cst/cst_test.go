@@ -0,0 +1,231 @@
+package cst
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+)
+
+// fragments are glued together at random to produce inputs that are
+// mostly, but not always, valid Monkey.
+var fragments = []string{
+    "let", "return", "fn", "if", "else", "true", "false", "null",
+    "x", "foo", "_bar", "0", "5", "123", "99999999999999999999",
+    "=", "==", "!=", "!", "+", "-", "*", "/", "<", ">", "=>", "|>", "|",
+    ".", "?.", "??", "?", ",", ";", "(", ")", "{", "}",
+    " ", "  ", "\t", "\n", "\r\n", "// comment", "//", "\n// line\n",
+    "@", "#", "é", "\x00",
+}
+
+type source string
+
+func (source) Generate(r *rand.Rand, size int) reflect.Value {
+    var b strings.Builder
+    for i := r.Intn(size + 1); i > 0; i-- {
+        b.WriteString(fragments[r.Intn(len(fragments))])
+    }
+    return reflect.ValueOf(source(b.String()))
+}
+
+func TestPrintReproducesInput(t *testing.T) {
+    lossless := func(src source) bool {
+        return Parse(string(src)).String() == string(src)
+    }
+
+    if err := quick.Check(lossless, &quick.Config{MaxCount: 2000}); err != nil {
+        t.Error(err)
+    }
+
+    arbitrary := func(src string) bool {
+        return Parse(src).String() == src
+    }
+
+    if err := quick.Check(arbitrary, &quick.Config{MaxCount: 500}); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestRedTreeOffsets(t *testing.T) {
+    covers := func(src source) bool {
+        tree := Parse(string(src))
+        return checkOffsets(t, tree.Root(), string(src))
+    }
+
+    if err := quick.Check(covers, &quick.Config{MaxCount: 500}); err != nil {
+        t.Error(err)
+    }
+}
+
+// checkOffsets verifies that every element's offset and width select
+// exactly its text from src, and that parent links are consistent.
+func checkOffsets(t *testing.T, n *Node, src string) bool {
+    if src[n.Offset():n.Offset()+n.Width()] != n.String() {
+        t.Logf("node %s at %d does not match source", n.Kind(), n.Offset())
+        return false
+    }
+
+    for _, c := range n.Children() {
+        if c.Parent() != n {
+            t.Logf("child of %s has wrong parent", n.Kind())
+            return false
+        }
+        if src[c.Offset():c.Offset()+c.Width()] != c.String() {
+            t.Logf("child of %s at %d does not match source", n.Kind(), c.Offset())
+            return false
+        }
+        if c, ok := c.(*Node); ok && !checkOffsets(t, c, src) {
+            return false
+        }
+    }
+
+    return true
+}
+
+func TestToASTMatchesParser(t *testing.T) {
+    for _, input := range corpus.Programs {
+        p := parser.New(lexer.New(input))
+        expected := p.ParseProgram()
+        if len(p.Errors()) != 0 {
+            t.Fatalf("parser errors for %q: %v", input, p.Errors())
+        }
+
+        tree := Parse(input)
+        if len(tree.Errors()) != 0 {
+            t.Errorf("cst errors for %q: %v", input, tree.Errors())
+            continue
+        }
+
+        actual := tree.ToAST()
+        if !reflect.DeepEqual(actual, expected) {
+            t.Errorf("ToAST differs from parser for %q.\nexpected=%q\ngot=     %q",
+                input, expected.String(), actual.String())
+        }
+    }
+}
+
+// TestParsersAgree checks on random inputs that the builder rejects
+// whatever package parser rejects, and that both build the same tree
+// when the builder accepts the input. The builder is stricter in one
+// way: it reports a block left open at the end of the input, which the
+// parser closes silently.
+func TestParsersAgree(t *testing.T) {
+    agree := func(src source) bool {
+        p := parser.New(lexer.New(string(src)))
+        expected := p.ParseProgram()
+        tree := Parse(string(src))
+
+        if len(p.Errors()) != 0 && len(tree.Errors()) == 0 {
+            t.Logf("%q: parser errors %v, but no cst errors", src, p.Errors())
+            return false
+        }
+        if len(tree.Errors()) != 0 {
+            return true
+        }
+        if !reflect.DeepEqual(tree.ToAST(), expected) {
+            t.Logf("%q: parser built %q, cst built %q", src, expected.String(), tree.ToAST().String())
+            return false
+        }
+        return true
+    }
+
+    if err := quick.Check(agree, &quick.Config{MaxCount: 5000}); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestTrivia(t *testing.T) {
+    input := "// header\nlet x = 1; // one\n\n  x // done"
+
+    tree := Parse(input)
+    if len(tree.Errors()) != 0 {
+        t.Fatalf("cst errors: %v", tree.Errors())
+    }
+
+    tokens := tree.Root().Tokens()
+    tests := []struct {
+        text     string
+        leading  []Trivia
+        trailing []Trivia
+    }{
+        {"let", []Trivia{{Comment, "// header"}, {Whitespace, "\n"}}, []Trivia{{Whitespace, " "}}},
+        {"x", nil, []Trivia{{Whitespace, " "}}},
+        {"=", nil, []Trivia{{Whitespace, " "}}},
+        {"1", nil, nil},
+        {";", nil, []Trivia{{Whitespace, " "}, {Comment, "// one"}, {Whitespace, "\n"}}},
+        {"x", []Trivia{{Whitespace, "\n  "}}, []Trivia{{Whitespace, " "}, {Comment, "// done"}}},
+        {"", nil, nil},
+    }
+
+    if len(tokens) != len(tests) {
+        t.Fatalf("expected %d tokens, got=%d", len(tests), len(tokens))
+    }
+
+    for i, tt := range tests {
+        tok := tokens[i]
+        if tok.Text() != tt.text {
+            t.Errorf("tokens[%d] text wrong. expected=%q, got=%q", i, tt.text, tok.Text())
+        }
+        if len(tok.Leading())+len(tt.leading) > 0 && !reflect.DeepEqual(tok.Leading(), tt.leading) {
+            t.Errorf("tokens[%d] leading trivia wrong. expected=%q, got=%q", i, tt.leading, tok.Leading())
+        }
+        if len(tok.Trailing())+len(tt.trailing) > 0 && !reflect.DeepEqual(tok.Trailing(), tt.trailing) {
+            t.Errorf("tokens[%d] trailing trivia wrong. expected=%q, got=%q", i, tt.trailing, tok.Trailing())
+        }
+    }
+}
+
+func TestStructure(t *testing.T) {
+    tree := Parse("let f = (a, b) => a.b(c) |> g;")
+
+    stmt := tree.Root().Nodes()[0]
+    if stmt.Kind() != LetStatement {
+        t.Fatalf("expected LetStatement, got=%s", stmt.Kind())
+    }
+
+    arrow := stmt.Nodes()[1]
+    if arrow.Kind() != ArrowFunction {
+        t.Fatalf("expected ArrowFunction, got=%s", arrow.Kind())
+    }
+
+    kinds := []Kind{}
+    for _, n := range arrow.Nodes() {
+        kinds = append(kinds, n.Kind())
+    }
+    if !reflect.DeepEqual(kinds, []Kind{ParameterList, PipeExpression}) {
+        t.Errorf("wrong children of ArrowFunction, got=%v", kinds)
+    }
+
+    if arrow.Parent() != nil && arrow.Parent().Kind() != LetStatement {
+        t.Errorf("arrow.Parent() is not the LetStatement, got=%s", arrow.Parent().Kind())
+    }
+
+    if arrow.String() != "(a, b) => a.b(c) |> g" {
+        t.Errorf("arrow.String() wrong, got=%q", arrow.String())
+    }
+}
+
+func TestErrorsKeepTokens(t *testing.T) {
+    input := "let = ; ) fn(1) { @ }"
+
+    tree := Parse(input)
+    if tree.String() != input {
+        t.Errorf("tree.String() wrong. expected=%q, got=%q", input, tree.String())
+    }
+
+    expected := []string{
+        "1:5: expected next token to be IDENT, got = instead",
+        "1:7: no prefix parse function for ; found",
+        "1:9: no prefix parse function for ) found",
+        "1:14: expected next token to be IDENT, got INT instead",
+        "1:19: no prefix parse function for ILLEGAL found",
+    }
+    if !reflect.DeepEqual(tree.Errors(), expected) {
+        t.Errorf("tree.Errors() wrong.\nexpected=%q\ngot=     %q", expected, tree.Errors())
+    }
+}
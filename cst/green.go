@@ -0,0 +1,185 @@
+// Package cst implements a lossless concrete syntax tree for Monkey.
+//
+// The tree is built in two layers. The green layer holds immutable
+// nodes that own their tokens, including the whitespace and comments
+// around them, and know only their width. The red layer wraps green
+// nodes on demand with a parent pointer and an absolute offset. Printing
+// a tree reproduces its source byte for byte, and ToAST projects it
+// onto the types in package ast.
+package cst
+
+import (
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+type TriviaKind int
+
+const (
+    Whitespace TriviaKind = iota
+    Comment
+    // Skipped is source the lexer never reached, such as anything
+    // after a NUL byte.
+    Skipped
+)
+
+// Trivia is text between tokens that does not affect the syntax tree.
+type Trivia struct {
+    Kind TriviaKind
+    Text string
+}
+
+type Kind int
+
+const (
+    SourceFile Kind = iota
+    LetStatement
+    ReturnStatement
+    ExpressionStatement
+    Block
+    Identifier
+    IntegerLiteral
+    BooleanLiteral
+    NullLiteral
+    PrefixExpression
+    InfixExpression
+    ParenExpression
+    IfExpression
+    FunctionLiteral
+    ArrowFunction
+    ParameterList
+    CallExpression
+    ArgumentList
+    MemberExpression
+    PipeExpression
+    // TypeAnnotation is `: T` after a let name or parameter, and
+    // ResultType is `-> T` after a parameter list.
+    TypeAnnotation
+    ResultType
+    NamedType
+    GenericType
+    FuncType
+    TypeList
+    // Error holds tokens that could not be parsed.
+    Error
+)
+
+var kindNames = [...]string{
+    SourceFile:             "SourceFile",
+    LetStatement:           "LetStatement",
+    ReturnStatement:        "ReturnStatement",
+    ExpressionStatement:    "ExpressionStatement",
+    Block:                  "Block",
+    Identifier:             "Identifier",
+    IntegerLiteral:         "IntegerLiteral",
+    BooleanLiteral:         "BooleanLiteral",
+    NullLiteral:            "NullLiteral",
+    PrefixExpression:       "PrefixExpression",
+    InfixExpression:        "InfixExpression",
+    ParenExpression:        "ParenExpression",
+    IfExpression:           "IfExpression",
+    FunctionLiteral:        "FunctionLiteral",
+    ArrowFunction:          "ArrowFunction",
+    ParameterList:          "ParameterList",
+    CallExpression:         "CallExpression",
+    ArgumentList:           "ArgumentList",
+    MemberExpression:       "MemberExpression",
+    PipeExpression:         "PipeExpression",
+    TypeAnnotation:         "TypeAnnotation",
+    ResultType:             "ResultType",
+    NamedType:              "NamedType",
+    GenericType:            "GenericType",
+    FuncType:               "FuncType",
+    TypeList:               "TypeList",
+    Error:                  "Error",
+}
+
+func (k Kind) String() string {
+    if k < 0 || int(k) >= len(kindNames) {
+        return "Kind(?)"
+    }
+    return kindNames[k]
+}
+
+// A GreenElement is a *GreenNode or a *GreenToken.
+type GreenElement interface {
+    // Width is the length in bytes of the element's source text,
+    // including trivia.
+    Width() int
+    writeTo(b *strings.Builder)
+}
+
+// GreenToken is a token together with the trivia on either side of it.
+// Trailing trivia runs up to and including the end of the token's line;
+// everything after that belongs to the next token's leading trivia.
+type GreenToken struct {
+    Type        token.TokenType
+    Text        string
+    Leading     []Trivia
+    Trailing    []Trivia
+}
+
+func (t *GreenToken) Width() int {
+    return triviaWidth(t.Leading) + len(t.Text) + triviaWidth(t.Trailing)
+}
+
+func (t *GreenToken) writeTo(b *strings.Builder) {
+    for _, tr := range t.Leading {
+        b.WriteString(tr.Text)
+    }
+    b.WriteString(t.Text)
+    for _, tr := range t.Trailing {
+        b.WriteString(tr.Text)
+    }
+}
+
+func triviaWidth(trivia []Trivia) int {
+    w := 0
+    for _, tr := range trivia {
+        w += len(tr.Text)
+    }
+    return w
+}
+
+// GreenNode is an immutable syntax node. It does not know its position
+// or parent, so identical subtrees can be shared.
+type GreenNode struct {
+    Kind        Kind
+    Children    []GreenElement
+    width       int
+}
+
+// NewGreenNode returns a node of the given kind, skipping nil children.
+func NewGreenNode(kind Kind, children ...GreenElement) *GreenNode {
+    n := &GreenNode{Kind: kind, Children: []GreenElement{}}
+
+    for _, c := range children {
+        if c == nil {
+            continue
+        }
+        if g, ok := c.(*GreenNode); ok && g == nil {
+            continue
+        }
+        n.Children = append(n.Children, c)
+        n.width += c.Width()
+    }
+
+    return n
+}
+
+func (n *GreenNode) Width() int {
+    return n.width
+}
+
+func (n *GreenNode) writeTo(b *strings.Builder) {
+    for _, c := range n.Children {
+        c.writeTo(b)
+    }
+}
+
+func (n *GreenNode) String() string {
+    var b strings.Builder
+    n.writeTo(&b)
+    return b.String()
+}
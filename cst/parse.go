@@ -0,0 +1,503 @@
+package cst
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Parse builds a concrete syntax tree for src. It never fails: tokens
+// that do not fit the grammar are kept in Error nodes and reported by
+// Tree.Errors, so the tree always prints back to src.
+func Parse(src string) *Tree {
+    p := &builder{tokens: scan(src), errors: []string{}}
+
+    statements := []GreenElement{}
+    for !p.curTokenIs(token.EOF) {
+        statements = append(statements, p.parseStatement())
+    }
+    statements = append(statements, p.next())
+
+    lines := []int{0}
+    for i := 0; i < len(src); i++ {
+        if src[i] == '\n' {
+            lines = append(lines, i+1)
+        }
+    }
+
+    return &Tree{
+        root:   NewGreenNode(SourceFile, statements...),
+        lines:  lines,
+        errors: p.errors,
+    }
+}
+
+type scanned struct {
+    green   *GreenToken
+    pos     token.Position
+}
+
+// scan splits src into green tokens, attaching the text between tokens
+// as trivia. The last token is always EOF.
+func scan(src string) []scanned {
+    l := lexer.New(src)
+    tokens := []scanned{}
+    end := 0
+
+    for {
+        tok := l.NextToken()
+
+        start := tok.Pos.Offset
+        if tok.Type == token.EOF {
+            start = len(src)
+        }
+
+        gap := splitTrivia(src[end:start])
+        if tok.Type == token.EOF && tok.Pos.Offset < len(src) {
+            gap = splitTrivia(src[end:tok.Pos.Offset])
+            gap = append(gap, Trivia{Kind: Skipped, Text: src[tok.Pos.Offset:]})
+        }
+
+        leading := gap
+        if len(tokens) > 0 {
+            var trailing []Trivia
+            trailing, leading = splitTrailing(gap)
+            tokens[len(tokens)-1].green.Trailing = trailing
+        }
+
+        tokens = append(tokens, scanned{
+            green:  &GreenToken{Type: tok.Type, Text: tok.Literal, Leading: leading},
+            pos:    tok.Pos,
+        })
+
+        if tok.Type == token.EOF {
+            return tokens
+        }
+        end = start + len(tok.Literal)
+    }
+}
+
+// splitTrivia breaks the text between two tokens into runs of
+// whitespace and `//` comments.
+func splitTrivia(s string) []Trivia {
+    trivia := []Trivia{}
+
+    for len(s) > 0 {
+        if strings.HasPrefix(s, "//") {
+            end := strings.IndexByte(s, '\n')
+            if end < 0 {
+                end = len(s)
+            }
+            trivia = append(trivia, Trivia{Kind: Comment, Text: s[:end]})
+            s = s[end:]
+            continue
+        }
+
+        end := strings.Index(s, "//")
+        if end < 0 {
+            end = len(s)
+        }
+        trivia = append(trivia, Trivia{Kind: Whitespace, Text: s[:end]})
+        s = s[end:]
+    }
+
+    return trivia
+}
+
+// splitTrailing divides trivia following a token into the part on the
+// token's line, up to and including the newline, and the rest.
+func splitTrailing(trivia []Trivia) (trailing, leading []Trivia) {
+    for i, tr := range trivia {
+        if tr.Kind != Whitespace {
+            continue
+        }
+        nl := strings.IndexByte(tr.Text, '\n')
+        if nl < 0 {
+            continue
+        }
+
+        trailing = append(trailing, trivia[:i]...)
+        trailing = append(trailing, Trivia{Kind: Whitespace, Text: tr.Text[:nl+1]})
+        if rest := tr.Text[nl+1:]; rest != "" {
+            leading = append(leading, Trivia{Kind: Whitespace, Text: rest})
+        }
+        return trailing, append(leading, trivia[i+1:]...)
+    }
+
+    return trivia, nil
+}
+
+// builder mirrors the Pratt parser in package parser, but keeps every
+// token it consumes and can look ahead arbitrarily far. It reads the
+// same tokens, from package lexer, and takes operator precedences from
+// parser.Precedence; TestToASTMatchesParser checks that the two agree
+// on the trees they build.
+type builder struct {
+    tokens  []scanned
+    current int
+    errors  []string
+}
+
+func (p *builder) cur() scanned {
+    return p.tokens[p.current]
+}
+
+func (p *builder) curTokenIs(t token.TokenType) bool {
+    return p.cur().green.Type == t
+}
+
+func (p *builder) peekTokenIs(t token.TokenType) bool {
+    if p.current+1 >= len(p.tokens) {
+        return false
+    }
+    return p.tokens[p.current+1].green.Type == t
+}
+
+func (p *builder) curPrecedence() int {
+    return parser.Precedence(p.cur().green.Type)
+}
+
+// next consumes the current token. EOF is never consumed.
+func (p *builder) next() *GreenToken {
+    tok := p.cur().green
+    if tok.Type != token.EOF {
+        p.current++
+    }
+    return tok
+}
+
+func (p *builder) errorf(format string, args ...interface{}) {
+    msg := fmt.Sprintf(format, args...)
+    p.errors = append(p.errors, fmt.Sprintf("%s: %s", p.cur().pos, msg))
+}
+
+// expect consumes the current token if it has type t, and records an
+// error and returns nil otherwise.
+func (p *builder) expect(t token.TokenType) GreenElement {
+    if p.curTokenIs(t) {
+        return p.next()
+    }
+
+    p.errorf("expected next token to be %s, got %s instead", t, p.cur().green.Type)
+    return nil
+}
+
+func (p *builder) optional(t token.TokenType) GreenElement {
+    if p.curTokenIs(t) {
+        return p.next()
+    }
+    return nil
+}
+
+func (p *builder) parseStatement() *GreenNode {
+    switch p.cur().green.Type {
+    case token.LET:
+        return p.parseLetStatement()
+    case token.RETURN:
+        return p.parseReturnStatement()
+    default:
+        return p.parseExpressionStatement()
+    }
+}
+
+func (p *builder) parseLetStatement() *GreenNode {
+    let := p.next()
+
+    var name GreenElement
+    if p.curTokenIs(token.IDENT) {
+        name = NewGreenNode(Identifier, p.next(), p.parseTypeAnnotation())
+    } else {
+        p.expect(token.IDENT)
+    }
+
+    assign := p.expect(token.ASSIGN)
+    if assign == nil {
+        return NewGreenNode(LetStatement, let, name)
+    }
+
+    value := p.parseExpression(parser.LOWEST)
+
+    return NewGreenNode(LetStatement, let, name, assign, value, p.optional(token.SEMICOLON))
+}
+
+func (p *builder) parseReturnStatement() *GreenNode {
+    ret := p.next()
+    value := p.parseExpression(parser.LOWEST)
+
+    return NewGreenNode(ReturnStatement, ret, value, p.optional(token.SEMICOLON))
+}
+
+func (p *builder) parseExpressionStatement() *GreenNode {
+    expression := p.parseExpression(parser.LOWEST)
+
+    return NewGreenNode(ExpressionStatement, expression, p.optional(token.SEMICOLON))
+}
+
+// parseExpression returns nil only at EOF; any other token without a
+// prefix parse function is consumed into an Error node.
+func (p *builder) parseExpression(precedence int) GreenElement {
+    var left GreenElement
+
+    switch p.cur().green.Type {
+    case token.IDENT:
+        ident := NewGreenNode(Identifier, p.next())
+        if p.curTokenIs(token.ARROW) {
+            return p.parseArrowFunction(ident)
+        }
+        left = ident
+    case token.INT:
+        if _, err := strconv.ParseInt(p.cur().green.Text, 0, 64); err != nil {
+            p.errorf("could not parse %q as an int", p.cur().green.Text)
+        }
+        left = NewGreenNode(IntegerLiteral, p.next())
+    case token.TRUE, token.FALSE:
+        left = NewGreenNode(BooleanLiteral, p.next())
+    case token.NULL:
+        left = NewGreenNode(NullLiteral, p.next())
+    case token.BANG, token.MINUS:
+        op := p.next()
+        left = NewGreenNode(PrefixExpression, op, p.parseExpression(parser.PREFIX))
+    case token.LPAREN:
+        if p.isArrowParameterList() {
+            return p.parseArrowFunction(p.parseParameterList())
+        }
+        open := p.next()
+        inner := p.parseExpression(parser.LOWEST)
+        left = NewGreenNode(ParenExpression, open, inner, p.expect(token.RPAREN))
+    case token.IF:
+        left = p.parseIfExpression()
+    case token.FUNCTION:
+        fn := p.next()
+        params := p.parseParameterList()
+        var result GreenElement
+        if p.curTokenIs(token.THINARROW) {
+            arrow := p.next()
+            result = NewGreenNode(ResultType, arrow, p.parseType())
+        }
+        left = NewGreenNode(FunctionLiteral, fn, params, result, p.parseBlock())
+    case token.EOF:
+        p.errorf("no prefix parse function for %s found", token.EOF)
+        return nil
+    default:
+        p.errorf("no prefix parse function for %s found", p.cur().green.Type)
+        return NewGreenNode(Error, p.next())
+    }
+
+    for !p.curTokenIs(token.SEMICOLON) && precedence < p.curPrecedence() {
+        switch p.cur().green.Type {
+        case token.LPAREN:
+            left = NewGreenNode(CallExpression, left, p.parseArgumentList())
+        case token.DOT, token.OPTDOT:
+            dot := p.next()
+            var property GreenElement
+            if p.curTokenIs(token.IDENT) {
+                property = NewGreenNode(Identifier, p.next())
+            } else {
+                p.expect(token.IDENT)
+            }
+            left = NewGreenNode(MemberExpression, left, dot, property)
+        case token.PIPE:
+            precedence := p.curPrecedence()
+            op := p.next()
+            left = NewGreenNode(PipeExpression, left, op, p.parseExpression(precedence))
+        default:
+            precedence := p.curPrecedence()
+            op := p.next()
+            left = NewGreenNode(InfixExpression, left, op, p.parseExpression(precedence))
+        }
+    }
+
+    return left
+}
+
+// isArrowParameterList reports whether the parenthesis at the current
+// token is closed by a parenthesis followed by `=>`.
+func (p *builder) isArrowParameterList() bool {
+    depth := 0
+    for i := p.current; i < len(p.tokens); i++ {
+        switch p.tokens[i].green.Type {
+        case token.LPAREN:
+            depth++
+        case token.RPAREN:
+            depth--
+            if depth == 0 {
+                return i+1 < len(p.tokens) && p.tokens[i+1].green.Type == token.ARROW
+            }
+        case token.EOF:
+            return false
+        }
+    }
+    return false
+}
+
+// parseArrowFunction is called with the `=>` token as the current token.
+func (p *builder) parseArrowFunction(params GreenElement) *GreenNode {
+    arrow := p.next()
+
+    if p.curTokenIs(token.LBRACE) {
+        return NewGreenNode(ArrowFunction, params, arrow, p.parseBlock())
+    }
+
+    return NewGreenNode(ArrowFunction, params, arrow, p.parseExpression(parser.LOWEST))
+}
+
+func (p *builder) parseIfExpression() *GreenNode {
+    children := []GreenElement{p.next()}
+
+    children = append(children, p.expect(token.LPAREN))
+    children = append(children, p.parseExpression(parser.LOWEST))
+    children = append(children, p.expect(token.RPAREN))
+
+    if !p.curTokenIs(token.LBRACE) {
+        p.expect(token.LBRACE)
+        return NewGreenNode(IfExpression, children...)
+    }
+    children = append(children, p.parseBlock())
+
+    if p.curTokenIs(token.ELSE) {
+        children = append(children, p.next())
+        if p.curTokenIs(token.LBRACE) {
+            children = append(children, p.parseBlock())
+        } else {
+            p.expect(token.LBRACE)
+        }
+    }
+
+    return NewGreenNode(IfExpression, children...)
+}
+
+func (p *builder) parseBlock() GreenElement {
+    if !p.curTokenIs(token.LBRACE) {
+        p.expect(token.LBRACE)
+        return nil
+    }
+
+    children := []GreenElement{p.next()}
+    for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+        children = append(children, p.parseStatement())
+    }
+    children = append(children, p.expect(token.RBRACE))
+
+    return NewGreenNode(Block, children...)
+}
+
+// parseParameterList parses the parameters of a function literal or a
+// parenthesized arrow function, which may be annotated.
+func (p *builder) parseParameterList() GreenElement {
+    if !p.curTokenIs(token.LPAREN) {
+        p.expect(token.LPAREN)
+        return nil
+    }
+
+    children := []GreenElement{p.next()}
+    for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+        if len(children) > 1 {
+            if comma := p.expect(token.COMMA); comma == nil {
+                break
+            } else {
+                children = append(children, comma)
+            }
+        }
+
+        if p.curTokenIs(token.IDENT) {
+            ident := p.next()
+            children = append(children, NewGreenNode(Identifier, ident, p.parseTypeAnnotation()))
+        } else {
+            p.errorf("expected next token to be %s, got %s instead", token.IDENT, p.cur().green.Type)
+            children = append(children, NewGreenNode(Error, p.next()))
+        }
+    }
+    children = append(children, p.expect(token.RPAREN))
+
+    return NewGreenNode(ParameterList, children...)
+}
+
+func (p *builder) parseArgumentList() GreenElement {
+    children := []GreenElement{p.next()}
+
+    for !p.curTokenIs(token.RPAREN) && !p.curTokenIs(token.EOF) {
+        if len(children) > 1 {
+            if comma := p.expect(token.COMMA); comma == nil {
+                break
+            } else {
+                children = append(children, comma)
+            }
+        }
+        children = append(children, p.parseExpression(parser.LOWEST))
+    }
+    children = append(children, p.expect(token.RPAREN))
+
+    return NewGreenNode(ArgumentList, children...)
+}
+
+// parseTypeAnnotation parses `: T` if the current token is a colon, and
+// returns nil otherwise.
+func (p *builder) parseTypeAnnotation() GreenElement {
+    if !p.curTokenIs(token.COLON) {
+        return nil
+    }
+
+    colon := p.next()
+    return NewGreenNode(TypeAnnotation, colon, p.parseType())
+}
+
+// parseType parses `name`, `name<T, ...>` or `fn(T, ...) -> T`. A token
+// that cannot start a type is consumed into an Error node, except EOF.
+func (p *builder) parseType() GreenElement {
+    switch p.cur().green.Type {
+    case token.IDENT:
+        named := NewGreenNode(NamedType, p.next())
+        if !p.curTokenIs(token.LT) {
+            return named
+        }
+        open := p.next()
+        if p.curTokenIs(token.GT) {
+            p.errorf("empty type argument list")
+        }
+        return NewGreenNode(GenericType, named, p.parseTypeList(open, token.GT))
+
+    case token.FUNCTION:
+        fn := p.next()
+        if !p.curTokenIs(token.LPAREN) {
+            p.expect(token.LPAREN)
+            return NewGreenNode(FuncType, fn)
+        }
+        params := p.parseTypeList(p.next(), token.RPAREN)
+        arrow := p.expect(token.THINARROW)
+        if arrow == nil {
+            return NewGreenNode(FuncType, fn, params)
+        }
+        return NewGreenNode(FuncType, fn, params, arrow, p.parseType())
+
+    case token.EOF:
+        p.errorf("expected a type, got %s instead", token.EOF)
+        return nil
+    }
+
+    p.errorf("expected a type, got %s instead", p.cur().green.Type)
+    return NewGreenNode(Error, p.next())
+}
+
+// parseTypeList parses the types after the opening token open, `(` or
+// `<`, up to the closing token end.
+func (p *builder) parseTypeList(open GreenElement, end token.TokenType) GreenElement {
+    children := []GreenElement{open}
+
+    for !p.curTokenIs(end) && !p.curTokenIs(token.EOF) {
+        if len(children) > 1 {
+            if comma := p.expect(token.COMMA); comma == nil {
+                break
+            } else {
+                children = append(children, comma)
+            }
+        }
+        children = append(children, p.parseType())
+    }
+    children = append(children, p.expect(end))
+
+    return NewGreenNode(TypeList, children...)
+}
@@ -0,0 +1,191 @@
+package cst
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Tree is the result of parsing a source file.
+type Tree struct {
+    root    *GreenNode
+    lines   []int // offsets of the first byte of each line
+    errors  []string
+}
+
+// Root returns the red SourceFile node of the tree.
+func (t *Tree) Root() *Node {
+    return &Node{tree: t, green: t.root}
+}
+
+// Errors returns the syntax errors found while parsing.
+func (t *Tree) Errors() []string {
+    return t.errors
+}
+
+// String returns the source the tree was parsed from.
+func (t *Tree) String() string {
+    return t.root.String()
+}
+
+// position converts a byte offset into a token.Position.
+func (t *Tree) position(offset int) token.Position {
+    i := sort.Search(len(t.lines), func(i int) bool { return t.lines[i] > offset }) - 1
+    return token.Position{Offset: offset, Line: i + 1, Column: offset - t.lines[i] + 1}
+}
+
+// An Element is a *Node or a *Token.
+type Element interface {
+    Parent() *Node
+    // Offset is the byte offset of the element's first byte, including
+    // leading trivia.
+    Offset() int
+    Width() int
+    String() string
+}
+
+// Node is a position-aware view of a GreenNode.
+type Node struct {
+    tree    *Tree
+    green   *GreenNode
+    parent  *Node
+    offset  int
+}
+
+func (n *Node) Kind() Kind {
+    return n.green.Kind
+}
+
+func (n *Node) Green() *GreenNode {
+    return n.green
+}
+
+func (n *Node) Parent() *Node {
+    return n.parent
+}
+
+func (n *Node) Offset() int {
+    return n.offset
+}
+
+func (n *Node) Width() int {
+    return n.green.Width()
+}
+
+// String returns the node's source text, including trivia.
+func (n *Node) String() string {
+    return n.green.String()
+}
+
+// Children returns the child nodes and tokens of n in source order.
+func (n *Node) Children() []Element {
+    children := []Element{}
+    offset := n.offset
+
+    for _, c := range n.green.Children {
+        switch c := c.(type) {
+        case *GreenNode:
+            children = append(children, &Node{tree: n.tree, green: c, parent: n, offset: offset})
+        case *GreenToken:
+            children = append(children, &Token{tree: n.tree, green: c, parent: n, offset: offset})
+        }
+        offset += c.Width()
+    }
+
+    return children
+}
+
+// Nodes returns the child nodes of n, skipping tokens.
+func (n *Node) Nodes() []*Node {
+    nodes := []*Node{}
+    for _, c := range n.Children() {
+        if c, ok := c.(*Node); ok {
+            nodes = append(nodes, c)
+        }
+    }
+    return nodes
+}
+
+// Tokens returns all tokens in the subtree rooted at n.
+func (n *Node) Tokens() []*Token {
+    tokens := []*Token{}
+    for _, c := range n.Children() {
+        switch c := c.(type) {
+        case *Node:
+            tokens = append(tokens, c.Tokens()...)
+        case *Token:
+            tokens = append(tokens, c)
+        }
+    }
+    return tokens
+}
+
+// FirstToken returns the first token of n, or nil if n is empty.
+func (n *Node) FirstToken() *Token {
+    for _, c := range n.Children() {
+        switch c := c.(type) {
+        case *Token:
+            return c
+        case *Node:
+            if t := c.FirstToken(); t != nil {
+                return t
+            }
+        }
+    }
+    return nil
+}
+
+// Token is a position-aware view of a GreenToken.
+type Token struct {
+    tree    *Tree
+    green   *GreenToken
+    parent  *Node
+    offset  int
+}
+
+func (t *Token) Type() token.TokenType {
+    return t.green.Type
+}
+
+// Text returns the token's text without trivia.
+func (t *Token) Text() string {
+    return t.green.Text
+}
+
+func (t *Token) Leading() []Trivia {
+    return t.green.Leading
+}
+
+func (t *Token) Trailing() []Trivia {
+    return t.green.Trailing
+}
+
+func (t *Token) Parent() *Node {
+    return t.parent
+}
+
+func (t *Token) Offset() int {
+    return t.offset
+}
+
+func (t *Token) Width() int {
+    return t.green.Width()
+}
+
+// Pos returns the position of the token's text, after leading trivia.
+func (t *Token) Pos() token.Position {
+    return t.tree.position(t.offset + triviaWidth(t.green.Leading))
+}
+
+// Token converts t back into the token produced by the lexer.
+func (t *Token) Token() token.Token {
+    return token.Token{Type: t.green.Type, Literal: t.green.Text, Pos: t.Pos()}
+}
+
+// String returns the token's text, including trivia.
+func (t *Token) String() string {
+    var b strings.Builder
+    t.green.writeTo(&b)
+    return b.String()
+}
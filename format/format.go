@@ -0,0 +1,501 @@
+// Package format implements canonical formatting of Monkey source.
+//
+// The output is valid Monkey: parsing it yields the same syntax tree
+// as the input, and formatting already formatted source is a no-op.
+// Source keeps comments, placing each one either at the end of the line
+// of the statement it follows or on its own line before the next
+// statement.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/cst"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+const (
+    indentation = "    "
+
+    // maxWidth is the column after which call arguments are broken
+    // onto their own lines.
+    maxWidth = 80
+)
+
+// Operator precedences, as used by the parser. Operands are wrapped in
+// parentheses only when their precedence is lower than their context
+// requires.
+var precedences = map[string]int{
+    "|>":   parser.PIPE,
+    "==":   parser.EQUALS,
+    "!=":   parser.EQUALS,
+    "??":   parser.COALESCE,
+    "<":    parser.LESSGREATER,
+    ">":    parser.LESSGREATER,
+    "+":    parser.SUM,
+    "-":    parser.SUM,
+    "*":    parser.PRODUCT,
+    "/":    parser.PRODUCT,
+}
+
+// primary is the precedence of expressions that never need parentheses.
+const primary = parser.CALL + 1
+
+// Error is returned by Source when the input does not parse.
+type Error struct {
+    Errors []string
+}
+
+func (e *Error) Error() string {
+    return "format: " + strings.Join(e.Errors, "; ")
+}
+
+// Source formats src in canonical Monkey style. If src does not parse,
+// the parser errors are returned as an *Error.
+func Source(src []byte) ([]byte, error) {
+    p := parser.New(lexer.New(string(src)))
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        return nil, &Error{Errors: p.Errors()}
+    }
+
+    pr := &printer{}
+    pr.comments, pr.ends = comments(string(src))
+    pr.node(program)
+
+    if pr.err != nil {
+        return nil, pr.err
+    }
+
+    return pr.buf.Bytes(), nil
+}
+
+// comment is a `//` comment in the source being formatted. A trailing
+// comment follows a token on the same line; after is the offset just
+// past that token.
+type comment struct {
+    offset      int
+    text        string
+    trailing    bool
+    after       int
+}
+
+// comments returns the comments in src, and maps the offset of each
+// statement to the offset just past its last token.
+func comments(src string) ([]comment, map[int]int) {
+    list := []comment{}
+    ends := map[int]int{}
+
+    var visit func(n *cst.Node)
+    visit = func(n *cst.Node) {
+        switch n.Kind() {
+        case cst.LetStatement, cst.ReturnStatement, cst.ExpressionStatement:
+            tokens := n.Tokens()
+            if len(tokens) > 0 {
+                last := tokens[len(tokens)-1]
+                ends[tokens[0].Pos().Offset] = last.Pos().Offset + len(last.Text())
+            }
+        }
+        for _, c := range n.Nodes() {
+            visit(c)
+        }
+    }
+    root := cst.Parse(src).Root()
+    visit(root)
+
+    for _, tok := range root.Tokens() {
+        offset := tok.Offset()
+        for _, tr := range tok.Leading() {
+            if tr.Kind == cst.Comment {
+                list = append(list, comment{offset: offset, text: tr.Text})
+            }
+            offset += len(tr.Text)
+        }
+
+        offset += len(tok.Text())
+        after := offset
+        for _, tr := range tok.Trailing() {
+            if tr.Kind == cst.Comment {
+                list = append(list, comment{offset: offset, text: tr.Text, trailing: true, after: after})
+            }
+            offset += len(tr.Text)
+        }
+    }
+
+    return list, ends
+}
+
+// Node writes the canonical form of node to w. Programs and statements
+// are terminated by a newline; expressions are not.
+func Node(w io.Writer, node ast.Node) error {
+    pr := &printer{}
+    pr.node(node)
+
+    if pr.err != nil {
+        return pr.err
+    }
+
+    _, err := w.Write(pr.buf.Bytes())
+    return err
+}
+
+// String returns the canonical form of node, or a description of the
+// problem if node cannot be printed.
+func String(node ast.Node) string {
+    var buf bytes.Buffer
+
+    if err := Node(&buf, node); err != nil {
+        return err.Error()
+    }
+
+    return buf.String()
+}
+
+type printer struct {
+    buf     bytes.Buffer
+    indent  int
+    err     error
+
+    // comments not yet printed, and the end offsets of statements
+    comments    []comment
+    ends        map[int]int
+}
+
+func (pr *printer) errorf(format string, args ...interface{}) {
+    if pr.err == nil {
+        pr.err = fmt.Errorf("format: "+format, args...)
+    }
+}
+
+func (pr *printer) print(s string) {
+    pr.buf.WriteString(s)
+}
+
+func (pr *printer) newline() {
+    pr.buf.WriteString("\n")
+    pr.buf.WriteString(strings.Repeat(indentation, pr.indent))
+}
+
+// column returns the width of the current output line.
+func (pr *printer) column() int {
+    b := pr.buf.Bytes()
+    return len(b) - (bytes.LastIndexByte(b, '\n') + 1)
+}
+
+func (pr *printer) node(node ast.Node) {
+    switch n := node.(type) {
+    case *ast.Program:
+        for i, s := range n.Statements {
+            for pr.commentBefore(offset(s)) {
+                pr.print(pr.nextComment() + "\n")
+            }
+            pr.statementWithComments(s, n.Statements[i+1:])
+            pr.print("\n")
+        }
+        for pr.commentBefore(math.MaxInt) {
+            pr.print(pr.nextComment() + "\n")
+        }
+    case *ast.BlockStatement:
+        pr.block(n)
+    case ast.Statement:
+        pr.statement(n)
+        pr.print("\n")
+    case ast.Expression:
+        pr.expression(n, parser.LOWEST)
+    case ast.TypeExpr:
+        pr.print(n.String())
+    default:
+        pr.errorf("unexpected node type %T", n)
+    }
+}
+
+func (pr *printer) statement(s ast.Statement) {
+    switch s := s.(type) {
+    case *ast.LetStatement:
+        pr.print("let ")
+        pr.print(s.Name.String())
+        pr.print(" = ")
+        pr.expression(s.Value, parser.LOWEST)
+        pr.print(";")
+
+    case *ast.ReturnStatement:
+        pr.print("return")
+        if s.ReturnValue != nil {
+            pr.print(" ")
+            pr.expression(s.ReturnValue, parser.LOWEST)
+        }
+        pr.print(";")
+
+    case *ast.ExpressionStatement:
+        pr.expression(s.Expression, parser.LOWEST)
+        if _, ok := s.Expression.(*ast.IfExpression); !ok {
+            pr.print(";")
+        }
+
+    default:
+        pr.errorf("unexpected statement type %T", s)
+    }
+}
+
+func (pr *printer) block(b *ast.BlockStatement) {
+    if len(b.Statements) == 0 {
+        pr.print("{}")
+        return
+    }
+
+    pr.print("{")
+    pr.indent++
+    for i, s := range b.Statements {
+        pr.newline()
+        for pr.commentBefore(offset(s)) {
+            pr.print(pr.nextComment())
+            pr.newline()
+        }
+        pr.statementWithComments(s, b.Statements[i+1:])
+    }
+    pr.indent--
+    pr.newline()
+    pr.print("}")
+}
+
+// offset returns the source offset of the first token of s.
+func offset(s ast.Statement) int {
+    switch s := s.(type) {
+    case *ast.LetStatement:
+        return s.Token.Pos.Offset
+    case *ast.ReturnStatement:
+        return s.Token.Pos.Offset
+    case *ast.ExpressionStatement:
+        return s.Token.Pos.Offset
+    }
+    return 0
+}
+
+func (pr *printer) commentBefore(offset int) bool {
+    return len(pr.comments) > 0 && pr.comments[0].offset < offset
+}
+
+func (pr *printer) nextComment() string {
+    c := pr.comments[0]
+    pr.comments = pr.comments[1:]
+    return c.text
+}
+
+// statementWithComments prints s followed by the comments trailing
+// its last token. rest are the statements following s in the same list.
+func (pr *printer) statementWithComments(s ast.Statement, rest []ast.Statement) {
+    pr.statement(s)
+    pr.separate(s, rest)
+
+    end, ok := pr.ends[offset(s)]
+    for ok && len(pr.comments) > 0 && pr.comments[0].trailing && pr.comments[0].after == end {
+        pr.print(" " + pr.nextComment())
+    }
+}
+
+// separate terminates an if expression statement with a semicolon
+// when the statement after it would otherwise be parsed as its
+// continuation, as in `if (x) { y }; (a)(b)` or `...; -z`.
+func (pr *printer) separate(s ast.Statement, rest []ast.Statement) {
+    es, ok := s.(*ast.ExpressionStatement)
+    if !ok || len(rest) == 0 {
+        return
+    }
+    if _, ok := es.Expression.(*ast.IfExpression); !ok {
+        return
+    }
+
+    next := &printer{}
+    next.statement(rest[0])
+    if b := next.buf.Bytes(); len(b) > 0 && (b[0] == '(' || b[0] == '-') {
+        pr.print(";")
+    }
+}
+
+// precedence returns how tightly e binds when printed without
+// parentheses.
+func precedence(e ast.Expression) int {
+    switch e := e.(type) {
+    case *ast.InfixExpression:
+        return precedences[e.Operator]
+    case *ast.PipeExpression:
+        return parser.PIPE
+    case *ast.PrefixExpression:
+        return parser.PREFIX
+    case *ast.CallExpression, *ast.MemberExpression:
+        return parser.CALL
+    case *ast.FunctionLiteral:
+        // the body of `x => body` extends as far to the right as
+        // possible, so it has to be parenthesized as an operand
+        if e.ImplicitReturn {
+            return parser.LOWEST
+        }
+    }
+
+    return primary
+}
+
+// expression prints e, wrapped in parentheses if its precedence is
+// lower than prec.
+func (pr *printer) expression(e ast.Expression, prec int) {
+    if e == nil {
+        pr.errorf("missing expression")
+        return
+    }
+
+    if p := precedence(e); p < prec || (p == parser.LOWEST && prec > parser.LOWEST) {
+        pr.print("(")
+        pr.expression(e, parser.LOWEST)
+        pr.print(")")
+        return
+    }
+
+    switch e := e.(type) {
+    case *ast.Identifier:
+        pr.print(e.Value)
+
+    case *ast.IntegerLiteral:
+        pr.print(e.Token.Literal)
+
+    case *ast.Boolean:
+        pr.print(fmt.Sprintf("%t", e.Value))
+
+    case *ast.NullLiteral:
+        pr.print("null")
+
+    case *ast.Placeholder:
+        pr.print("$" + e.Name)
+
+    case *ast.PrefixExpression:
+        pr.print(e.Operator)
+        pr.expression(e.Right, parser.PREFIX)
+
+    case *ast.InfixExpression:
+        p := precedences[e.Operator]
+        pr.expression(e.Left, p)
+        pr.print(" " + e.Operator + " ")
+        pr.expression(e.Right, p+1)
+
+    case *ast.PipeExpression:
+        pr.expression(e.Left, parser.PIPE)
+        pr.print(" |> ")
+        pr.expression(e.Right, parser.PIPE+1)
+
+    case *ast.MemberExpression:
+        pr.expression(e.Object, parser.CALL)
+        if e.Optional {
+            pr.print("?.")
+        } else {
+            pr.print(".")
+        }
+        pr.print(e.Property.Value)
+
+    case *ast.CallExpression:
+        pr.expression(e.Function, parser.CALL)
+        pr.arguments(e.Arguments)
+
+    case *ast.IfExpression:
+        pr.print("if (")
+        pr.expression(e.Condition, parser.LOWEST)
+        pr.print(") ")
+        pr.block(e.Consequence)
+        if e.Alternative != nil {
+            pr.print(" else ")
+            pr.block(e.Alternative)
+        }
+
+    case *ast.FunctionLiteral:
+        pr.function(e)
+
+    default:
+        pr.errorf("unexpected expression type %T", e)
+    }
+}
+
+func (pr *printer) function(fl *ast.FunctionLiteral) {
+    params := []string{}
+    for _, p := range fl.Parameters {
+        params = append(params, p.String())
+    }
+
+    if fl.Token.Type != token.ARROW {
+        pr.print("fn(" + strings.Join(params, ", ") + ") ")
+        if fl.Result != nil {
+            pr.print("-> " + fl.Result.String() + " ")
+        }
+        pr.block(fl.Body)
+        return
+    }
+
+    // the arrow shorthand has no syntax for a result type, and only the
+    // parenthesized form has room for parameter annotations
+    if fl.Result != nil {
+        pr.errorf("arrow function has a result type annotation")
+        return
+    }
+
+    if len(params) == 1 && fl.Parameters[0].Annotation == nil {
+        pr.print(params[0] + " => ")
+    } else {
+        pr.print("(" + strings.Join(params, ", ") + ") => ")
+    }
+
+    if !fl.ImplicitReturn {
+        pr.block(fl.Body)
+        return
+    }
+
+    if len(fl.Body.Statements) != 1 {
+        pr.errorf("arrow function body has %d statements", len(fl.Body.Statements))
+        return
+    }
+    body, ok := fl.Body.Statements[0].(*ast.ExpressionStatement)
+    if !ok {
+        pr.errorf("arrow function body is a %T", fl.Body.Statements[0])
+        return
+    }
+    pr.expression(body.Expression, parser.LOWEST)
+}
+
+// arguments prints a parenthesized argument list, on one line if it
+// fits within maxWidth and otherwise with one argument per line.
+func (pr *printer) arguments(args []ast.Expression) {
+    flat := &printer{indent: pr.indent}
+    for i, arg := range args {
+        if i > 0 {
+            flat.print(", ")
+        }
+        flat.expression(arg, parser.LOWEST)
+    }
+    if flat.err != nil {
+        pr.errorf("%s", strings.TrimPrefix(flat.err.Error(), "format: "))
+        return
+    }
+
+    s := flat.buf.String()
+    if len(args) < 2 || strings.Contains(s, "\n") || pr.column()+len(s)+1 <= maxWidth {
+        pr.print("(" + s + ")")
+        return
+    }
+
+    pr.print("(")
+    pr.indent++
+    for i, arg := range args {
+        pr.newline()
+        pr.expression(arg, parser.LOWEST)
+        if i < len(args)-1 {
+            pr.print(",")
+        }
+    }
+    pr.indent--
+    pr.newline()
+    pr.print(")")
+}
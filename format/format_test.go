@@ -0,0 +1,161 @@
+package format
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func TestSource(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"let   x=5", "let x = 5;\n"},
+        {"1+2*3", "1 + 2 * 3;\n"},
+        {"(1+2)*3", "(1 + 2) * 3;\n"},
+        {"a-(b-c)", "a - (b - c);\n"},
+        {"(a-b)-c", "a - b - c;\n"},
+        {"-(-a)", "--a;\n"},
+        {"!(a==b)", "!(a == b);\n"},
+        {"a ?? (b == c)", "a ?? (b == c);\n"},
+        {"(a ?? b) == c", "a ?? b == c;\n"},
+        {"x|>f(y)|>g", "x |> f(y) |> g;\n"},
+        {"x |> (f |> g)", "x |> (f |> g);\n"},
+        {"(a+b).c", "(a + b).c;\n"},
+        {"a?.b.c(d,e)", "a?.b.c(d, e);\n"},
+        {"(x)=>x*2", "x => x * 2;\n"},
+        {"(x => x)(1)", "(x => x)(1);\n"},
+        {"map(xs, (a,b) => a)", "map(xs, (a, b) => a);\n"},
+        {"f(x => x |> g)", "f(x => x |> g);\n"},
+        {"let f = fn(x,y){x+y}", "let f = fn(x, y) {\n    x + y;\n};\n"},
+        {"let f = fn(){}", "let f = fn() {};\n"},
+        {"(a, b) => { a }", "(a, b) => {\n    a;\n};\n"},
+        {"if(x<y){x}else{y}", "if (x < y) {\n    x;\n} else {\n    y;\n}\n"},
+        {"if (x) { y }; -z", "if (x) {\n    y;\n};\n-z;\n"},
+        {"if (x) { y } z", "if (x) {\n    y;\n}\nz;\n"},
+        {"return   a", "return a;\n"},
+        {"null", "null;\n"},
+        {"let x:int=5", "let x: int = 5;\n"},
+        {"(x:int)=>x", "(x: int) => x;\n"},
+        {"(a:int,b)=>{a}", "(a: int, b) => {\n    a;\n};\n"},
+        {"let f = fn(a:int,b)->list<int>{a}", "let f = fn(a: int, b) -> list<int> {\n    a;\n};\n"},
+        {"let g:fn(int,bool)->fn()->int = h", "let g: fn(int, bool) -> fn() -> int = h;\n"},
+        {
+            "fn(n){if(n<2){return n;} return fib(n-1)+fib(n-2);}",
+            "fn(n) {\n    if (n < 2) {\n        return n;\n    }\n    return fib(n - 1) + fib(n - 2);\n};\n",
+        },
+        {
+            "let result = someFunctionName(firstArgumentValue, secondArgumentValue, thirdArgumentValue);",
+            "let result = someFunctionName(\n    firstArgumentValue,\n    secondArgumentValue,\n    thirdArgumentValue\n);\n",
+        },
+    }
+
+    for _, tt := range tests {
+        out, err := Source([]byte(tt.input))
+        if err != nil {
+            t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+        }
+
+        if string(out) != tt.expected {
+            t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=     %q", tt.input, tt.expected, out)
+        }
+    }
+}
+
+// TestRoundTrip checks that formatting preserves the syntax tree and
+// that formatting is idempotent.
+func TestRoundTrip(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+
+        out, err := Source([]byte(input))
+        if err != nil {
+            t.Fatalf("Source(%q) returned error: %s", input, err)
+        }
+
+        reparsed := testutil.Parse(t, string(out))
+        if reparsed.String() != program.String() {
+            t.Errorf("formatting %q changed the program.\nexpected=%q\ngot=     %q\nformatted:\n%s",
+                input, program.String(), reparsed.String(), out)
+        }
+
+        again, err := Source(out)
+        if err != nil {
+            t.Fatalf("Source(%q) returned error: %s", out, err)
+        }
+
+        if string(again) != string(out) {
+            t.Errorf("formatting is not idempotent.\nfirst:\n%s\nsecond:\n%s", out, again)
+        }
+    }
+}
+
+func TestSourceErrors(t *testing.T) {
+    _, err := Source([]byte("let = 5;"))
+
+    var formatErr *Error
+    if !errors.As(err, &formatErr) {
+        t.Fatalf("expected *Error, got=%T (%v)", err, err)
+    }
+
+    expected := "expected next token to be IDENT, got = instead"
+    if len(formatErr.Errors) == 0 || formatErr.Errors[0] != expected {
+        t.Errorf("expected error %q, got=%v", expected, formatErr.Errors)
+    }
+}
+
+func TestNodeExpression(t *testing.T) {
+    program := testutil.Parse(t, "let x = a * (b + c);")
+    value := program.Statements[0].(*ast.LetStatement).Value
+
+    if String(value) != "a * (b + c)" {
+        t.Errorf("String(value) wrong, got=%q", String(value))
+    }
+}
+
+func TestSourceComments(t *testing.T) {
+    input := `// header
+let x=1; // one
+let f = fn(a) {
+  // inside
+  a+1 // add
+  // dangling
+};
+if (x) { x } // trailing if
+// footer`
+
+    expected := `// header
+let x = 1; // one
+let f = fn(a) {
+    // inside
+    a + 1; // add
+};
+// dangling
+if (x) {
+    x;
+} // trailing if
+// footer
+`
+
+    out, err := Source([]byte(input))
+    if err != nil {
+        t.Fatalf("Source returned error: %s", err)
+    }
+
+    if string(out) != expected {
+        t.Errorf("Source wrong.\nexpected=%q\ngot=     %q", expected, out)
+    }
+
+    again, err := Source(out)
+    if err != nil {
+        t.Fatalf("Source returned error: %s", err)
+    }
+
+    if string(again) != string(out) {
+        t.Errorf("formatting comments is not idempotent.\nfirst:\n%s\nsecond:\n%s", out, again)
+    }
+}
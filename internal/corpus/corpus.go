@@ -0,0 +1,120 @@
+// Package corpus holds the Monkey programs used by round-trip tests
+// across packages: everything the parser tests exercise, plus larger
+// programs mixing all of the syntax.
+package corpus
+
+var Programs = []string{
+    "let x = 5;",
+    "let y = true;",
+    "let foobar = y;",
+    "return 5;",
+    "return true;",
+    "return foobar;",
+    "foobar;",
+    "5;",
+    "!5;",
+    "-15;",
+    "!foobar;",
+    "-foobar;",
+    "!true;",
+    "!false;",
+    "5 + 5;",
+    "5 - 5;",
+    "5 * 5;",
+    "5 / 5;",
+    "5 > 5;",
+    "5 < 5;",
+    "5 == 5;",
+    "5 != 5;",
+    "true == true",
+    "true != false",
+    "false == false",
+    "a ?? 5",
+    "-a * b",
+    "!-a",
+    "a + b + c",
+    "a + b - c",
+    "a * b * c",
+    "a * b / c",
+    "a + b / c",
+    "a + b * c + d / e - f",
+    "3 + 4; -5 * 5",
+    "5 > 4 == 3 < 4",
+    "5 < 4 != 3 > 4",
+    "3 + 4 * 5 == 3 * 1 + 4 * 5",
+    "3 > 5 == false",
+    "3 < 5 == true",
+    "1 + (2 + 3) + 4",
+    "(5 + 5) * 2",
+    "2 / (5 + 5)",
+    "-(5 + 5)",
+    "!(true == true)",
+    "a - (b - c)",
+    "x |> f(y) |> g",
+    "a + b |> f(c * d)",
+    "a == b |> f",
+    "-a.b",
+    "a.b * c.d",
+    "x |> list.push",
+    "a ?? b == c",
+    "-a ?? b",
+    "a ?? b ?? c",
+    "a ?? b < c",
+    "a ?? (b ?? c)",
+    "a?.b ?? null",
+    "null;",
+    "if (x < y) { x }",
+    "if (x < y) { x } else { y }",
+    "fn(x, y) { x + y; }",
+    "fn() {};",
+    "fn(x) {};",
+    "fn(x, y, z) {};",
+    "add(1, 2 * 3, 4 + 5);",
+    "x => x * 2;",
+    "(x) => x;",
+    "() => 1;",
+    "(a, b) => a + b;",
+    "(a, b) => { a + b; }",
+    "map(list, x => x * 2, (a, b) => a);",
+    "x |> add(1, 2);",
+    "user.name;",
+    "user?.name;",
+    "a.b(c).d;",
+    "(x => x)(1);",
+    "(a + b).c;",
+    "fn(x) { x }(5);",
+    "let curry = fn(a) { fn(b) { fn(c) { a + b + c } } };",
+    "let x: int = 5;",
+    "fn(a: int, b: bool) -> int { a }",
+    "fn(a, b: int) { a + b }",
+    "map(xs, (x: int, i) => x * i);",
+    "let f: fn(int, fn(int) -> bool) -> list<int> = g;",
+    `let fib = fn(n) {
+    if (n < 2) {
+        return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+};
+fib(10);`,
+    `let sum = fn(n, acc) {
+    if (n == 0) { acc } else { sum(n - 1, acc + n) }
+};
+let result = sum(100, 0) |> print;`,
+    `let compose = (f, g) => x => f(g(x));
+let inc = x => x + 1;
+let twice = compose(inc, inc);
+twice(5) |> log.info;
+let name = user?.profile.name ?? guest ?? null;`,
+    `let apply: fn(fn(int) -> int, int) -> int = fn(f: fn(int) -> int, x: int) -> int {
+    f(x)
+};
+let twice: fn(int) -> int = fn(n: int) -> int { n * 2 };
+apply(twice, 21);`,
+    `// comments are skipped by the lexer
+let x = 1; // one
+let f = fn(a) {
+    // inside
+    a / x // halved
+};
+f(x) // done`,
+}
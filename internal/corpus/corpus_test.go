@@ -0,0 +1,19 @@
+package corpus
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+)
+
+func TestProgramsParse(t *testing.T) {
+    for _, input := range Programs {
+        p := parser.New(lexer.New(input))
+        p.ParseProgram()
+
+        if len(p.Errors()) != 0 {
+            t.Errorf("parser errors for %q: %v", input, p.Errors())
+        }
+    }
+}
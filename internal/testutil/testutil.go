@@ -0,0 +1,22 @@
+// Package testutil holds helpers shared by the tests of several
+// packages.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+)
+
+// Parse parses input, failing the test if it has parser errors.
+func Parse(t testing.TB, input string) *ast.Program {
+    t.Helper()
+    p := parser.New(lexer.New(input))
+    program := p.ParseProgram()
+    if len(p.Errors()) != 0 {
+        t.Fatalf("parser errors for %q: %v", input, p.Errors())
+    }
+    return program
+}
@@ -10,16 +10,37 @@ type Lexer struct {
     position        int
     readPosition    int
     ch              byte
+
+    // line and column of ch
+    line            int
+    column          int
+
+    // template enables $identifier placeholders
+    template        bool
 }
 
 func New(input string) *Lexer {
-    l := &Lexer{input: input}
+    l := &Lexer{input: input, line: 1}
     l.readChar()
 
     return l
 }
 
+// NewTemplate returns a lexer that also recognizes $identifier
+// placeholders, returned as PLACEHOLDER tokens.
+func NewTemplate(input string) *Lexer {
+    l := New(input)
+    l.template = true
+
+    return l
+}
+
 func (l *Lexer) readChar() {
+    if l.ch == '\n' {
+        l.line += 1
+        l.column = 0
+    }
+
     if l.readPosition >= len(l.input) {
         l.ch = 0
     } else {
@@ -28,6 +49,7 @@ func (l *Lexer) readChar() {
 
     l.position = l.readPosition
     l.readPosition += 1
+    l.column += 1
 }
 
 
@@ -36,6 +58,8 @@ func (l *Lexer) NextToken() token.Token {
 
     l.skipWhitespace()
 
+    pos := token.Position{Offset: l.position, Line: l.line, Column: l.column}
+
     switch l.ch {
     case '=':
         if l.peekChar() == '=' {
@@ -43,11 +67,23 @@ func (l *Lexer) NextToken() token.Token {
             l.readChar()
             literal := string(ch) + string(l.ch)
             tok = token.Token{Type: token.EQ, Literal: literal}
+        } else if l.peekChar() == '>' {
+            ch := l.ch
+            l.readChar()
+            literal := string(ch) + string(l.ch)
+            tok = token.Token{Type: token.ARROW, Literal: literal}
         } else {
             tok = newToken(token.ASSIGN, l.ch)
         }
     case '-':
-        tok = newToken(token.MINUS, l.ch)
+        if l.peekChar() == '>' {
+            ch := l.ch
+            l.readChar()
+            literal := string(ch) + string(l.ch)
+            tok = token.Token{Type: token.THINARROW, Literal: literal}
+        } else {
+            tok = newToken(token.MINUS, l.ch)
+        }
     case '!':
         if l.peekChar() == '=' {
             ch := l.ch
@@ -71,6 +107,8 @@ func (l *Lexer) NextToken() token.Token {
         tok = newToken(token.RPAREN, l.ch)
     case ';':
         tok = newToken(token.SEMICOLON, l.ch)
+    case ':':
+        tok = newToken(token.COLON, l.ch)
     case '{':
         tok = newToken(token.LBRACE, l.ch)
     case '}':
@@ -79,6 +117,42 @@ func (l *Lexer) NextToken() token.Token {
         tok = newToken(token.COMMA, l.ch)
     case '+':
         tok = newToken(token.PLUS, l.ch)
+    case '|':
+        if l.peekChar() == '>' {
+            ch := l.ch
+            l.readChar()
+            literal := string(ch) + string(l.ch)
+            tok = token.Token{Type: token.PIPE, Literal: literal}
+        } else {
+            tok = newToken(token.ILLEGAL, l.ch)
+        }
+    case '.':
+        tok = newToken(token.DOT, l.ch)
+    case '?':
+        if l.peekChar() == '.' {
+            ch := l.ch
+            l.readChar()
+            literal := string(ch) + string(l.ch)
+            tok = token.Token{Type: token.OPTDOT, Literal: literal}
+        } else if l.peekChar() == '?' {
+            ch := l.ch
+            l.readChar()
+            literal := string(ch) + string(l.ch)
+            tok = token.Token{Type: token.NULLISH, Literal: literal}
+        } else {
+            tok = newToken(token.ILLEGAL, l.ch)
+        }
+    case '$':
+        if l.template && isLetter(l.peekChar()) {
+            l.readChar()
+            tok.Literal = "$" + l.readIdentifier()
+            tok.Type = token.PLACEHOLDER
+            tok.Pos = pos
+
+            return tok
+        } else {
+            tok = newToken(token.ILLEGAL, l.ch)
+        }
     case 0:
         tok.Literal = ""
         tok.Type = token.EOF
@@ -86,11 +160,13 @@ func (l *Lexer) NextToken() token.Token {
         if isLetter(l.ch) {
             tok.Literal = l.readIdentifier()
             tok.Type = token.LookupIdent(tok.Literal)
+            tok.Pos = pos
 
             return tok
         } else if isDigit(l.ch) {
             tok.Type = token.INT
             tok.Literal = l.readNumber()
+            tok.Pos = pos
 
             return tok
         } else {
@@ -98,6 +174,7 @@ func (l *Lexer) NextToken() token.Token {
         }
     }
 
+    tok.Pos = pos
     l.readChar()
     return tok
 }
@@ -140,11 +217,23 @@ func isDigit(ch byte) bool {
 }
 
 func newToken(tokenType token.TokenType, ch byte) token.Token {
-    return token.Token{Type:tokenType, Literal:string(ch)}
+    // string(ch) would convert bytes >= 0x80 to two-byte runes
+    return token.Token{Type:tokenType, Literal:string([]byte{ch})}
 }
 
+// skipWhitespace skips whitespace and `//` comments, which run to the
+// end of the line.
 func (l *Lexer) skipWhitespace() {
-    for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-        l.readChar()
+    for {
+        switch {
+        case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+            l.readChar()
+        case l.ch == '/' && l.peekChar() == '/':
+            for l.ch != '\n' && l.ch != 0 {
+                l.readChar()
+            }
+        default:
+            return
+        }
     }
 }
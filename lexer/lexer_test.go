@@ -26,6 +26,11 @@ func TestNextToken(t *testing.T) {
 
     10 == 10;
     10 != 9;
+    x => x;
+    x |> f;
+    a.b?.c;
+    a ?? null;
+    let f: fn(int) -> int;
     `
 
     tests := []struct {
@@ -105,6 +110,34 @@ func TestNextToken(t *testing.T) {
 		{token.UNEQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "f"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.OPTDOT, "?."},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.NULLISH, "??"},
+		{token.NULL, "null"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "f"},
+		{token.COLON, ":"},
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "int"},
+		{token.RPAREN, ")"},
+		{token.THINARROW, "->"},
+		{token.IDENT, "int"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
     }
 
@@ -124,3 +157,147 @@ func TestNextToken(t *testing.T) {
     }
 }
 
+
+func TestComments(t *testing.T) {
+    input := `// leading comment
+let x = 5; // trailing comment
+x / 2 // x halved
+//`
+
+    tests := []struct {
+        expectedType    token.TokenType
+        expectedLiteral string
+    }{
+        {token.LET, "let"},
+        {token.IDENT, "x"},
+        {token.ASSIGN, "="},
+        {token.INT, "5"},
+        {token.SEMICOLON, ";"},
+        {token.IDENT, "x"},
+        {token.SLASH, "/"},
+        {token.INT, "2"},
+        {token.EOF, ""},
+    }
+
+    l := New(input)
+    for i, expected := range tests {
+        token := l.NextToken()
+        if token.Type != expected.expectedType {
+            t.Fatalf("tests[%d] - tokenType wrong. expected=%q, got=%q",
+            i, expected.expectedType, token.Type)
+        }
+
+        if token.Literal != expected.expectedLiteral {
+            t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+            i, expected.expectedLiteral, token.Literal)
+        }
+    }
+}
+
+func TestCommentPositions(t *testing.T) {
+    input := "x // a / b\n  / / y //\n//z"
+
+    tests := []struct {
+        expectedType    token.TokenType
+        expectedPos     token.Position
+    }{
+        {token.IDENT, token.Position{Offset: 0, Line: 1, Column: 1}},
+        {token.SLASH, token.Position{Offset: 13, Line: 2, Column: 3}},
+        {token.SLASH, token.Position{Offset: 15, Line: 2, Column: 5}},
+        {token.IDENT, token.Position{Offset: 17, Line: 2, Column: 7}},
+        {token.EOF, token.Position{Offset: 25, Line: 3, Column: 4}},
+    }
+
+    l := New(input)
+    for i, expected := range tests {
+        tok := l.NextToken()
+        if tok.Type != expected.expectedType || tok.Pos != expected.expectedPos {
+            t.Fatalf("tests[%d] - expected %q at %+v, got %q at %+v",
+            i, expected.expectedType, expected.expectedPos, tok.Type, tok.Pos)
+        }
+    }
+}
+
+func TestTokenPositions(t *testing.T) {
+    input := "let x = 10;\n  x == 10\n"
+
+    tests := []struct {
+        expectedLiteral string
+        expectedPos     token.Position
+    }{
+        {"let", token.Position{Offset: 0, Line: 1, Column: 1}},
+        {"x", token.Position{Offset: 4, Line: 1, Column: 5}},
+        {"=", token.Position{Offset: 6, Line: 1, Column: 7}},
+        {"10", token.Position{Offset: 8, Line: 1, Column: 9}},
+        {";", token.Position{Offset: 10, Line: 1, Column: 11}},
+        {"x", token.Position{Offset: 14, Line: 2, Column: 3}},
+        {"==", token.Position{Offset: 16, Line: 2, Column: 5}},
+        {"10", token.Position{Offset: 19, Line: 2, Column: 8}},
+        {"", token.Position{Offset: 22, Line: 3, Column: 1}},
+    }
+
+    l := New(input)
+    for i, expected := range tests {
+        token := l.NextToken()
+        if token.Literal != expected.expectedLiteral {
+            t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+            i, expected.expectedLiteral, token.Literal)
+        }
+
+        if token.Pos != expected.expectedPos {
+            t.Fatalf("tests[%d] - position wrong. expected=%+v, got=%+v",
+            i, expected.expectedPos, token.Pos)
+        }
+    }
+}
+
+func TestIllegalBytes(t *testing.T) {
+    input := "x é"
+
+    l := New(input)
+    l.NextToken()
+
+    for _, expected := range []string{"\xc3", "\xa9"} {
+        tok := l.NextToken()
+        if tok.Type != token.ILLEGAL {
+            t.Fatalf("tokenType wrong. expected=%q, got=%q", token.ILLEGAL, tok.Type)
+        }
+        if tok.Literal != expected {
+            t.Fatalf("literal wrong. expected=%q, got=%q", expected, tok.Literal)
+        }
+    }
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+    input := "let $name = $value + 1; $x $"
+
+    tests := []struct {
+        expectedType    token.TokenType
+        expectedLiteral string
+    }{
+        {token.LET, "let"},
+        {token.PLACEHOLDER, "$name"},
+        {token.ASSIGN, "="},
+        {token.PLACEHOLDER, "$value"},
+        {token.PLUS, "+"},
+        {token.INT, "1"},
+        {token.SEMICOLON, ";"},
+        {token.PLACEHOLDER, "$x"},
+        {token.ILLEGAL, "$"},
+        {token.EOF, ""},
+    }
+
+    l := NewTemplate(input)
+    for i, tt := range tests {
+        tok := l.NextToken()
+        if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+            t.Fatalf("tests[%d] - expected %q %q, got %q %q",
+                i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+        }
+    }
+
+    tok := New("$name").NextToken()
+    if tok.Type != token.ILLEGAL {
+        t.Fatalf("placeholder outside template mode: expected ILLEGAL, got %q", tok.Type)
+    }
+}
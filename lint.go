@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/lint"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
+	"github.com/UsamaHameed/monkey-interpreter/sarif"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+type lintResult struct {
+    File        string          `json:"file"`
+    Line        int             `json:"line"`
+    Column      int             `json:"column"`
+    lint.Diagnostic
+}
+
+// parseErrorRule is the SARIF rule of parser errors.
+const parseErrorRule = "parse-error"
+
+var sarifLevels = map[lint.Severity]string{
+    lint.Info:    sarif.Note,
+    lint.Warning: sarif.Warning,
+    lint.Error:   sarif.Error,
+}
+
+// ruleList parses a comma-separated list of rule names into config.
+func ruleList(config *lint.Config, list string, enabled bool) error {
+    for _, name := range strings.Split(list, ",") {
+        if name == "" {
+            continue
+        }
+        if lint.Lookup(lint.Rules, name) == nil {
+            return fmt.Errorf("unknown rule %q", name)
+        }
+        config.Rules[name] = enabled
+    }
+    return nil
+}
+
+func runLint(args []string) int {
+    flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+    format := flags.String("format", "text", "output format: text, json or sarif")
+    configFile := flags.String("config", "", "read rule settings from this JSON `file`")
+    enable := flags.String("enable", "", "comma-separated `rules` to enable")
+    disable := flags.String("disable", "", "comma-separated `rules` to disable")
+    flags.Usage = func() {
+        fmt.Fprintf(os.Stderr, "usage: monkey lint [flags] [file ...]\n")
+        flags.PrintDefaults()
+        fmt.Fprintf(os.Stderr, "\nrules:\n")
+        for _, r := range lint.Rules {
+            fmt.Fprintf(os.Stderr, "  %-20s %s\n", r.Name(), r.Doc())
+        }
+    }
+
+    if err := flags.Parse(args); err != nil {
+        return 2
+    }
+    if *format != "text" && *format != "json" && *format != "sarif" {
+        fmt.Fprintf(os.Stderr, "monkey lint: unknown format %q\n", *format)
+        return 2
+    }
+
+    config := &lint.Config{Rules: map[string]bool{}}
+    if *configFile != "" {
+        data, err := os.ReadFile(*configFile)
+        if err == nil {
+            config, err = lint.ParseConfig(data, lint.Rules)
+        }
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            return 2
+        }
+        if config.Rules == nil {
+            config.Rules = map[string]bool{}
+        }
+    }
+    for _, err := range []error{ruleList(config, *enable, true), ruleList(config, *disable, false)} {
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "monkey lint: %s\n", err)
+            return 2
+        }
+    }
+
+    files := flags.Args()
+    if len(files) == 0 {
+        files = []string{""}
+    }
+
+    linter := lint.New(config)
+    results := []lintResult{}
+    status := 0
+
+    log := sarif.NewBuilder("monkey", "")
+    log.AddRule(parseErrorRule, "reports source that does not parse", sarif.Error)
+    for _, r := range linter.Rules {
+        log.AddRule(r.Name(), r.Doc(), sarifLevels[lint.RuleSeverity(r)])
+    }
+
+    for _, name := range files {
+        src, err := readSource(name)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            status = 1
+            continue
+        }
+
+        uri := sarif.FileURI(displayName(name))
+
+        var program *ast.Program
+        if *format == "sarif" {
+            log.AddArtifact(uri, src)
+
+            p := parser.New(lexer.New(src))
+            program = p.ParseProgram()
+            for _, err := range p.ErrorList() {
+                log.AddResult(parseErrorRule, "", err.Msg, uri, err.Pos, token.Position{})
+            }
+            if len(p.ErrorList()) != 0 {
+                program = nil
+            }
+        } else {
+            program, _ = parseSource(displayName(name), src)
+        }
+        if program == nil {
+            status = 1
+            continue
+        }
+
+        for _, d := range linter.Lint(program, src) {
+            results = append(results, lintResult{displayName(name), d.Pos.Line, d.Pos.Column, d})
+            log.AddResult(d.Rule, sarifLevels[d.Severity], d.Message, uri, d.Pos, d.End)
+            if d.Severity >= lint.Warning {
+                status = 1
+            }
+        }
+    }
+
+    if *format == "sarif" {
+        if err := log.Log().Write(os.Stdout); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            return 1
+        }
+        return status
+    }
+
+    if *format == "json" {
+        data, _ := json.MarshalIndent(results, "", "  ")
+        fmt.Println(string(data))
+        return status
+    }
+
+    for _, r := range results {
+        fmt.Printf("%s:%s\n", r.File, r.Diagnostic)
+    }
+
+    return status
+}
@@ -0,0 +1,270 @@
+// Package lint checks Monkey programs for likely mistakes.
+//
+// Each check is a Rule. A Linter runs its enabled rules over a parsed
+// program and collects their diagnostics, dropping those suppressed by
+// a comment in the source:
+//
+//    let unused = 1; // monkeylint:ignore unused-let
+//
+//    // monkeylint:ignore
+//    if (true) { x }
+//
+// A suppression comment on its own line applies to the next line;
+// after code it applies to its own line. It names the rules it
+// suppresses, separated by commas and optionally followed by a reason,
+// or suppresses all of them if it names none. A
+// "monkeylint:file-ignore" comment applies to the whole file.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/resolve"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+type Severity int
+
+const (
+    Info Severity = iota
+    Warning
+    Error
+)
+
+var severityNames = [...]string{
+    Info:    "info",
+    Warning: "warning",
+    Error:   "error",
+}
+
+func (s Severity) String() string {
+    return severityNames[s]
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+    return json.Marshal(s.String())
+}
+
+// A Diagnostic is a problem reported by a rule. End, if valid, is the
+// position just after the reported text.
+type Diagnostic struct {
+    Rule        string          `json:"rule"`
+    Severity    Severity        `json:"severity"`
+    Pos         token.Position  `json:"-"`
+    End         token.Position  `json:"-"`
+    Message     string          `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+    return fmt.Sprintf("%s: %s (%s)", d.Pos, d.Message, d.Rule)
+}
+
+// A Rule is a single check.
+type Rule interface {
+    // Name is the identifier used in configs and suppression comments,
+    // such as "unused-let".
+    Name()  string
+    // Doc is a one-line description of what the rule reports.
+    Doc()   string
+    Check(pass *Pass)
+}
+
+// RuleSeverity returns the severity of r's diagnostics: that given by
+// its Severity method if it has one, and Warning otherwise.
+func RuleSeverity(r Rule) Severity {
+    if r, ok := r.(interface{ Severity() Severity }); ok {
+        return r.Severity()
+    }
+    return Warning
+}
+
+// A Pass is the input to one rule's Check and collects its reports.
+type Pass struct {
+    Program *ast.Program
+    Info    *resolve.Info
+
+    rule        Rule
+    diagnostics []Diagnostic
+}
+
+// Report records d, filling in the rule name.
+func (p *Pass) Report(d Diagnostic) {
+    d.Rule = p.rule.Name()
+    p.diagnostics = append(p.diagnostics, d)
+}
+
+// Reportf records a diagnostic at pos with the rule's severity.
+func (p *Pass) Reportf(pos token.Position, format string, args ...interface{}) {
+    p.ReportRangef(pos, token.Position{}, format, args...)
+}
+
+// ReportRangef records a diagnostic from pos up to end with the rule's
+// severity.
+func (p *Pass) ReportRangef(pos, end token.Position, format string, args ...interface{}) {
+    p.Report(Diagnostic{Severity: RuleSeverity(p.rule), Pos: pos, End: end, Message: fmt.Sprintf(format, args...)})
+}
+
+// Config enables and disables rules by name. Rules not listed are
+// enabled.
+type Config struct {
+    Rules map[string]bool `json:"rules"`
+}
+
+// ParseConfig parses a JSON config such as
+//
+//    {"rules": {"unused-let": false}}
+//
+// and checks that it only names rules in rules.
+func ParseConfig(data []byte, rules []Rule) (*Config, error) {
+    config := &Config{}
+    if err := json.Unmarshal(data, config); err != nil {
+        return nil, fmt.Errorf("lint config: %s", err)
+    }
+
+    for name := range config.Rules {
+        if Lookup(rules, name) == nil {
+            return nil, fmt.Errorf("lint config: unknown rule %q", name)
+        }
+    }
+
+    return config, nil
+}
+
+func (c *Config) Enabled(name string) bool {
+    if c == nil {
+        return true
+    }
+    enabled, ok := c.Rules[name]
+    return !ok || enabled
+}
+
+// Lookup returns the rule in rules named name, or nil.
+func Lookup(rules []Rule, name string) Rule {
+    for _, r := range rules {
+        if r.Name() == name {
+            return r
+        }
+    }
+    return nil
+}
+
+// A Linter runs Rules over programs. A nil Config enables every rule.
+type Linter struct {
+    Rules   []Rule
+    Config  *Config
+}
+
+// New returns a linter with the built-in rules.
+func New(config *Config) *Linter {
+    return &Linter{Rules: Rules, Config: config}
+}
+
+// Lint runs the enabled rules over program and returns their
+// diagnostics sorted by position. src is the program's source, read
+// for suppression comments; it may be empty.
+func (l *Linter) Lint(program *ast.Program, src string) []Diagnostic {
+    info, _ := resolve.Resolve(program)
+    ignored := suppressions(src)
+
+    diagnostics := []Diagnostic{}
+    for _, rule := range l.Rules {
+        if !l.Config.Enabled(rule.Name()) {
+            continue
+        }
+
+        pass := &Pass{Program: program, Info: info, rule: rule}
+        rule.Check(pass)
+
+        for _, d := range pass.diagnostics {
+            if !ignored.match(d) {
+                diagnostics = append(diagnostics, d)
+            }
+        }
+    }
+
+    sort.SliceStable(diagnostics, func(i, j int) bool {
+        return diagnostics[i].Pos.Offset < diagnostics[j].Pos.Offset
+    })
+
+    return diagnostics
+}
+
+const (
+    ignoreDirective     = "monkeylint:ignore"
+    fileIgnoreDirective = "monkeylint:file-ignore"
+)
+
+// ignoreSet maps a line, or 0 for the whole file, to the rules ignored
+// on it; a nil set means all rules.
+type ignoreSet map[int]map[string]bool
+
+func (s ignoreSet) add(line int, rules map[string]bool) {
+    if old, ok := s[line]; ok && (old == nil || rules == nil) {
+        s[line] = nil
+        return
+    } else if ok {
+        for name := range rules {
+            old[name] = true
+        }
+        return
+    }
+    s[line] = rules
+}
+
+func (s ignoreSet) match(d Diagnostic) bool {
+    for _, line := range []int{0, d.Pos.Line} {
+        if rules, ok := s[line]; ok && (rules == nil || rules[d.Rule]) {
+            return true
+        }
+    }
+    return false
+}
+
+// suppressions finds the suppression comments in src. Monkey has no
+// string literals, so every "//" starts a comment.
+func suppressions(src string) ignoreSet {
+    set := ignoreSet{}
+
+    for i, line := range strings.Split(src, "\n") {
+        start := strings.Index(line, "//")
+        if start < 0 {
+            continue
+        }
+        text := strings.TrimSpace(line[start+2:])
+
+        target := i + 1
+        if strings.TrimSpace(line[:start]) == "" {
+            target = i + 2
+        }
+
+        var args string
+        switch {
+        case strings.HasPrefix(text, fileIgnoreDirective):
+            args = text[len(fileIgnoreDirective):]
+            target = 0
+        case strings.HasPrefix(text, ignoreDirective):
+            args = text[len(ignoreDirective):]
+        default:
+            continue
+        }
+
+        if args != "" && args[0] != ' ' && args[0] != '\t' {
+            continue
+        }
+
+        var rules map[string]bool
+        if fields := strings.Fields(args); len(fields) > 0 {
+            rules = map[string]bool{}
+            for _, name := range strings.Split(fields[0], ",") {
+                rules[name] = true
+            }
+        }
+        set.add(target, rules)
+    }
+
+    return set
+}
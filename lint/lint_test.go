@@ -0,0 +1,208 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+func lintStrings(t *testing.T, linter *Linter, input string) []string {
+    got := []string{}
+    for _, d := range linter.Lint(testutil.Parse(t, input), input) {
+        got = append(got, d.String())
+    }
+    return got
+}
+
+func TestRules(t *testing.T) {
+    tests := []struct {
+        rule        Rule
+        input       string
+        expected    []string
+    }{
+        {UnusedLet, "let x = 1; let y = 2; y;", []string{"1:5: x declared and not used (unused-let)"}},
+        {UnusedLet, "let _x = 1;", []string{}},
+        {UnusedLet, "let f = fn(a) { let b = a; 1 }; f(1);", []string{"1:21: b declared and not used (unused-let)"}},
+        {UnusedLet, "let fib = fn(n) { fib(n) };", []string{"1:5: fib declared and not used (unused-let)"}},
+        {UnusedLet, "let x = 1; let x = x + 1; x;", []string{}},
+
+        {Unreachable, "return 1; let x = 2; x;", []string{"1:11: unreachable code (unreachable)"}},
+        {Unreachable, "fn() { return 1; 2 }", []string{"1:18: unreachable code (unreachable)"}},
+        {Unreachable, "fn() { if (x) { return 1; } 2 }", []string{}},
+        {Unreachable, "fn() { return 1; }", []string{}},
+
+        {SelfCompare, "x == x", []string{"1:1: comparison of x with itself is always true (self-compare)"}},
+        {SelfCompare, "a.b != a.b", []string{"1:1: comparison of a.b with itself is always false (self-compare)"}},
+        {SelfCompare, "(x + 1) < (x + 1)", []string{"1:2: comparison of (x + 1) with itself is always false (self-compare)"}},
+        {SelfCompare, "f() == f()", []string{}},
+        {SelfCompare, "x |> f == x |> f", []string{}},
+        {SelfCompare, "x + x", []string{}},
+        {SelfCompare, "x == y", []string{}},
+
+        {ConstantCondition, "if (true) { 1 }", []string{"1:5: condition is always true (constant-condition)"}},
+        {ConstantCondition, "if (!null) { 1 }", []string{"1:5: condition is always true (constant-condition)"}},
+        {ConstantCondition, "if (0) { 1 }", []string{"1:5: condition is always true (constant-condition)"}},
+        {ConstantCondition, "if (!-1) { 1 }", []string{"1:5: condition is always false (constant-condition)"}},
+        {ConstantCondition, "if (x) { 1 }", []string{}},
+
+        {NonTailRecursion, "let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } };", []string{"1:48: recursive call to fact is not in tail position (non-tail-recursion)"}},
+        {NonTailRecursion, "let loop = fn(n, acc) { if (n < 1) { acc } else { loop(n - 1, acc + n) } };", []string{}},
+        {NonTailRecursion, "let loop = fn(n) { if (n > 0) { return loop(n - 1); } 0 };", []string{}},
+        {NonTailRecursion, "let fib = fn(n) { fib(n - 1) + fib(n - 2) };", []string{"1:19: recursive call to fib is not in tail position (non-tail-recursion)", "1:32: recursive call to fib is not in tail position (non-tail-recursion)"}},
+        {NonTailRecursion, "let f = fn(n) { f(f(n)) };", []string{"1:19: recursive call to f is not in tail position (non-tail-recursion)"}},
+        {NonTailRecursion, "let f = fn(n) { fn() { f(n) } };", []string{}},
+        {NonTailRecursion, "let f = fn(n) { let f = fn(m) { m }; 1 + f(n) };", []string{}},
+        {NonTailRecursion, "let f = fn(n) { g(n) + 1 };", []string{}},
+    }
+
+    for _, tt := range tests {
+        linter := &Linter{Rules: []Rule{tt.rule}}
+        got := lintStrings(t, linter, tt.input)
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("%s on %q wrong.\nexpected=%q\ngot=     %q", tt.rule.Name(), tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestDiagnosticText(t *testing.T) {
+    tests := []struct {
+        rule        Rule
+        input       string
+        expected    string
+    }{
+        {UnusedLet, "let abc = 1;", "abc"},
+        {SelfCompare, "if (a.b == a.b) { 1 }", "a.b == a.b"},
+        {ConstantCondition, "if (!true) { 1 }", "!true"},
+        // calls have no recorded end
+        {NonTailRecursion, "let f = fn(n) { 1 + f(n) };", ""},
+    }
+
+    for _, tt := range tests {
+        linter := &Linter{Rules: []Rule{tt.rule}}
+        diagnostics := linter.Lint(testutil.Parse(t, tt.input), tt.input)
+        if len(diagnostics) != 1 {
+            t.Fatalf("%s on %q: expected 1 diagnostic, got %v", tt.rule.Name(), tt.input, diagnostics)
+        }
+
+        d := diagnostics[0]
+        got := ""
+        if d.End.IsValid() {
+            got = tt.input[d.Pos.Offset:d.End.Offset]
+        }
+        if got != tt.expected {
+            t.Errorf("%s on %q reported wrong text. expected=%q, got=%q", tt.rule.Name(), tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestRuleSeverity(t *testing.T) {
+    for _, r := range Rules {
+        expected := Warning
+        if r == NonTailRecursion {
+            expected = Info
+        }
+        if got := RuleSeverity(r); got != expected {
+            t.Errorf("%s has severity %s, expected %s", r.Name(), got, expected)
+        }
+    }
+}
+
+func TestLintSortsByPosition(t *testing.T) {
+    input := "let a = 1;\nif (true) { b == b }\nreturn 1;\nlet c = 2;"
+
+    expected := []string{
+        "1:5: a declared and not used (unused-let)",
+        "2:5: condition is always true (constant-condition)",
+        "2:13: comparison of b with itself is always true (self-compare)",
+        "4:1: unreachable code (unreachable)",
+        "4:5: c declared and not used (unused-let)",
+    }
+
+    got := lintStrings(t, New(nil), input)
+    if fmt.Sprint(got) != fmt.Sprint(expected) {
+        t.Errorf("wrong diagnostics.\nexpected=%q\ngot=     %q", expected, got)
+    }
+}
+
+func TestConfig(t *testing.T) {
+    config, err := ParseConfig([]byte(`{"rules": {"unused-let": false, "self-compare": true}}`), Rules)
+    if err != nil {
+        t.Fatalf("ParseConfig returned error: %s", err)
+    }
+
+    if config.Enabled("unused-let") || !config.Enabled("self-compare") || !config.Enabled("unreachable") {
+        t.Errorf("wrong rules enabled: %v", config.Rules)
+    }
+
+    got := lintStrings(t, New(config), "let a = 1; a == a;")
+    expected := []string{"1:12: comparison of a with itself is always true (self-compare)"}
+    if fmt.Sprint(got) != fmt.Sprint(expected) {
+        t.Errorf("wrong diagnostics.\nexpected=%q\ngot=     %q", expected, got)
+    }
+
+    for _, data := range []string{`{"rules": {"no-such-rule": false}}`, `{"rules": [`} {
+        if _, err := ParseConfig([]byte(data), Rules); err == nil {
+            t.Errorf("ParseConfig(%s) expected an error", data)
+        }
+    }
+}
+
+func TestSuppressions(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {"let a = 1; // monkeylint:ignore unused-let", []string{}},
+        {"let a = 1; // monkeylint:ignore", []string{}},
+        {"let a = 1; // monkeylint:ignore self-compare", []string{"1:5: a declared and not used (unused-let)"}},
+        {"// monkeylint:ignore unused-let\nlet a = 1;", []string{}},
+        {"// monkeylint:ignore unused-let\n\nlet a = 1;", []string{"3:5: a declared and not used (unused-let)"}},
+        {"let a = 1; // monkeylint:ignore unused-let,self-compare kept for later\nb == b", []string{"2:1: comparison of b with itself is always true (self-compare)"}},
+        {"let a = 1; // monkeylint:ignored", []string{"1:5: a declared and not used (unused-let)"}},
+        {"// monkeylint:file-ignore unused-let\nlet a = 1;\nlet b = 2;\nif (true) { 1 }", []string{"4:5: condition is always true (constant-condition)"}},
+    }
+
+    for _, tt := range tests {
+        got := lintStrings(t, New(nil), tt.input)
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("Lint(%q) wrong.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+type countCalls struct{}
+
+func (countCalls) Name() string { return "count-calls" }
+func (countCalls) Doc() string  { return "reports every call" }
+func (countCalls) Check(pass *Pass) {
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        if call, ok := n.(*ast.CallExpression); ok {
+            pass.Report(Diagnostic{Severity: Info, Pos: ast.Pos(call), Message: "call"})
+        }
+        return true
+    })
+}
+
+func TestCustomRule(t *testing.T) {
+    linter := &Linter{Rules: []Rule{countCalls{}}}
+    diagnostics := linter.Lint(testutil.Parse(t, "f(g(1))"), "")
+
+    if len(diagnostics) != 2 {
+        t.Fatalf("expected 2 diagnostics, got %v", diagnostics)
+    }
+    for _, d := range diagnostics {
+        if d.Rule != "count-calls" || d.Severity != Info {
+            t.Errorf("wrong diagnostic %+v", d)
+        }
+    }
+}
+
+func TestLintCorpus(t *testing.T) {
+    linter := New(nil)
+    for _, input := range corpus.Programs {
+        linter.Lint(testutil.Parse(t, input), input)
+    }
+}
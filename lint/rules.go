@@ -0,0 +1,286 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/optimize"
+	"github.com/UsamaHameed/monkey-interpreter/resolve"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Rules are the built-in rules.
+var Rules = []Rule{
+    UnusedLet,
+    Unreachable,
+    SelfCompare,
+    ConstantCondition,
+    NonTailRecursion,
+}
+
+var (
+    UnusedLet           Rule = unusedLet{}
+    Unreachable         Rule = unreachable{}
+    SelfCompare         Rule = selfCompare{}
+    ConstantCondition   Rule = constantCondition{}
+    NonTailRecursion    Rule = nonTailRecursion{}
+)
+
+type unusedLet struct{}
+
+func (unusedLet) Name() string {
+    return "unused-let"
+}
+
+func (unusedLet) Doc() string {
+    return "reports let bindings that are never used; names starting with _ are exempt"
+}
+
+func (unusedLet) Check(pass *Pass) {
+    uses := map[*resolve.Object][]*ast.Identifier{}
+    for id, obj := range pass.Info.Uses {
+        uses[obj] = append(uses[obj], id)
+    }
+
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        let, ok := n.(*ast.LetStatement)
+        if !ok || let.Name == nil || strings.HasPrefix(let.Name.Value, "_") {
+            return true
+        }
+        obj := pass.Info.Defs[let.Name]
+        if obj == nil {
+            return true
+        }
+
+        // uses inside the binding's own value, as in a function that
+        // only calls itself, do not count
+        inside := map[ast.Node]bool{}
+        if let.Value != nil {
+            ast.Inspect(let.Value, func(n ast.Node) bool {
+                inside[n] = true
+                return true
+            })
+        }
+        for _, id := range uses[obj] {
+            if !inside[id] {
+                return true
+            }
+        }
+
+        pass.ReportRangef(let.Name.Token.Pos, end(let.Name), "%s declared and not used", let.Name.Value)
+        return true
+    })
+}
+
+type unreachable struct{}
+
+func (unreachable) Name() string {
+    return "unreachable"
+}
+
+func (unreachable) Doc() string {
+    return "reports statements after a return statement in the same block"
+}
+
+func (unreachable) Check(pass *Pass) {
+    check := func(list []ast.Statement) {
+        for i := 0; i+1 < len(list); i++ {
+            if _, ok := list[i].(*ast.ReturnStatement); ok {
+                pass.Reportf(ast.Pos(list[i+1]), "unreachable code")
+                return
+            }
+        }
+    }
+
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        switch n := n.(type) {
+        case *ast.Program:
+            check(n.Statements)
+        case *ast.BlockStatement:
+            check(n.Statements)
+        }
+        return true
+    })
+}
+
+type selfCompare struct{}
+
+func (selfCompare) Name() string {
+    return "self-compare"
+}
+
+func (selfCompare) Doc() string {
+    return "reports comparisons of an expression with itself, such as x == x"
+}
+
+// selfCompareResults holds the result of comparing a value with itself.
+var selfCompareResults = map[string]string{
+    "==": "true",
+    "!=": "false",
+    "<":  "false",
+    ">":  "false",
+}
+
+func (selfCompare) Check(pass *Pass) {
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        e, ok := n.(*ast.InfixExpression)
+        if !ok {
+            return true
+        }
+        result, ok := selfCompareResults[e.Operator]
+        if !ok || e.Left == nil || !ast.Equal(e.Left, e.Right) || hasCall(e.Left) {
+            return true
+        }
+
+        pass.ReportRangef(ast.Pos(e), end(e), "comparison of %s with itself is always %s", e.Left.String(), result)
+        return true
+    })
+}
+
+// end returns the position just after e if e ends in a token recorded
+// in the tree, and an invalid position otherwise. Closing parentheses
+// are not recorded, so calls have no end and that of `(a + b)` is that
+// of b.
+func end(e ast.Expression) token.Position {
+    var tok token.Token
+    switch e := e.(type) {
+    case *ast.Identifier:
+        tok = e.Token
+    case *ast.IntegerLiteral:
+        tok = e.Token
+    case *ast.Boolean:
+        tok = e.Token
+    case *ast.NullLiteral:
+        tok = e.Token
+    case *ast.Placeholder:
+        tok = e.Token
+    case *ast.PrefixExpression:
+        return end(e.Right)
+    case *ast.InfixExpression:
+        return end(e.Right)
+    case *ast.MemberExpression:
+        if e.Property != nil {
+            return end(e.Property)
+        }
+        return token.Position{}
+    default:
+        return token.Position{}
+    }
+
+    if !tok.Pos.IsValid() {
+        return token.Position{}
+    }
+    n := len(tok.Literal)
+    return token.Position{Offset: tok.Pos.Offset + n, Line: tok.Pos.Line, Column: tok.Pos.Column + n}
+}
+
+// hasCall reports whether evaluating n may call a function, whose
+// result could differ between calls.
+func hasCall(n ast.Node) bool {
+    found := false
+    ast.Inspect(n, func(n ast.Node) bool {
+        switch n.(type) {
+        case *ast.CallExpression, *ast.PipeExpression:
+            found = true
+        case *ast.FunctionLiteral:
+            return false
+        }
+        return !found
+    })
+    return found
+}
+
+type constantCondition struct{}
+
+func (constantCondition) Name() string {
+    return "constant-condition"
+}
+
+func (constantCondition) Doc() string {
+    return "reports if expressions whose condition is a literal"
+}
+
+func (constantCondition) Check(pass *Pass) {
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        e, ok := n.(*ast.IfExpression)
+        if !ok {
+            return true
+        }
+        if value, ok := truthiness(e.Condition); ok {
+            pass.ReportRangef(ast.Pos(e.Condition), end(e.Condition), "condition is always %t", value)
+        }
+        return true
+    })
+}
+
+// truthiness returns whether e is a literal, possibly negated, and if so
+// whether it counts as true. In Monkey null and false are false and
+// every integer, including 0, is true.
+func truthiness(e ast.Expression) (bool, bool) {
+    switch e := e.(type) {
+    case *ast.Boolean:
+        return e.Value, true
+    case *ast.NullLiteral:
+        return false, true
+    case *ast.IntegerLiteral:
+        return true, true
+    case *ast.PrefixExpression:
+        if e.Operator == "!" {
+            value, ok := truthiness(e.Right)
+            return !value, ok
+        }
+        if e.Operator == "-" {
+            if _, ok := e.Right.(*ast.IntegerLiteral); ok {
+                return true, true
+            }
+        }
+    }
+    return false, false
+}
+
+type nonTailRecursion struct{}
+
+func (nonTailRecursion) Name() string {
+    return "non-tail-recursion"
+}
+
+func (nonTailRecursion) Doc() string {
+    return "reports functions that call themselves other than in tail position, using stack space for each call"
+}
+
+// Severity is Info: deep recursion is not a mistake in itself.
+func (nonTailRecursion) Severity() Severity {
+    return Info
+}
+
+func (nonTailRecursion) Check(pass *Pass) {
+    tail := optimize.TailCalls(pass.Program)
+
+    ast.Inspect(pass.Program, func(n ast.Node) bool {
+        let, ok := n.(*ast.LetStatement)
+        if !ok || let.Name == nil {
+            return true
+        }
+        fl, ok := let.Value.(*ast.FunctionLiteral)
+        obj := pass.Info.Defs[let.Name]
+        if !ok || fl.Body == nil || obj == nil {
+            return true
+        }
+
+        // calls made by function literals in the body run in frames of
+        // their own
+        ast.Inspect(fl.Body, func(n ast.Node) bool {
+            switch n := n.(type) {
+            case *ast.FunctionLiteral:
+                return false
+            case *ast.CallExpression:
+                id, ok := n.Function.(*ast.Identifier)
+                if ok && pass.Info.Uses[id] == obj && !tail[n] {
+                    pass.Reportf(ast.Pos(n), "recursive call to %s is not in tail position", obj.Name)
+                }
+            }
+            return true
+        })
+        return true
+    })
+}
@@ -1,12 +1,65 @@
 package main
 
 import (
+    "flag"
     "fmt"
     "os"
+    "strings"
     "github.com/UsamaHameed/monkey-interpreter/repl"
 )
 
+func usage() {
+    fmt.Fprintf(os.Stderr, "usage: monkey [flags]                start the REPL\n")
+    fmt.Fprintf(os.Stderr, "       monkey -format=F [file ...]   report parser errors and lint findings\n")
+    fmt.Fprintf(os.Stderr, "                                     in format F: text, json or sarif\n")
+    fmt.Fprintf(os.Stderr, "       monkey ast [flags] [file]     print the syntax tree of a file\n")
+    fmt.Fprintf(os.Stderr, "       monkey lint [flags] [file ...]\n")
+    fmt.Fprintf(os.Stderr, "                                     report likely mistakes\n")
+}
+
+// run handles the flags given without a subcommand: -format checks
+// files as monkey lint does, and the others are REPL options.
+func run(args []string) int {
+    flags := flag.NewFlagSet("monkey", flag.ContinueOnError)
+    format := flags.String("format", "", "check the files, or standard input, and report in this `format`: text, json or sarif")
+    options := repl.Options{}
+    flags.BoolVar(&options.Tokens, "tokens", false, "print the tokens of each input")
+    flags.BoolVar(&options.Dot, "dot", false, "print each input as a Graphviz DOT graph")
+    flags.Usage = func() {
+        usage()
+        fmt.Fprintf(os.Stderr, "\nflags:\n")
+        flags.PrintDefaults()
+    }
+
+    if err := flags.Parse(args); err != nil {
+        return 2
+    }
+    if *format != "" {
+        return runLint(append([]string{"-format", *format}, flags.Args()...))
+    }
+    if flags.NArg() != 0 {
+        flags.Usage()
+        return 2
+    }
+
+    fmt.Printf("type some code\n")
+    repl.Run(os.Stdin, os.Stdout, options)
+
+    return 0
+}
+
 func main() {
-    fmt.Printf("type some code")
-    repl.Start(os.Stdin, os.Stdout)
+    if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+        switch os.Args[1] {
+        case "ast":
+            os.Exit(runAST(os.Args[2:]))
+        case "lint":
+            os.Exit(runLint(os.Args[2:]))
+        default:
+            usage()
+            os.Exit(2)
+        }
+    }
+
+    os.Exit(run(os.Args[1:]))
 }
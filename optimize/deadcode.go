@@ -0,0 +1,169 @@
+package optimize
+
+import (
+	"sort"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/astutil"
+	"github.com/UsamaHameed/monkey-interpreter/resolve"
+)
+
+// A Reason says why EliminateDeadCode removed a statement.
+type Reason int
+
+const (
+    // Unreachable statements follow a return statement.
+    Unreachable Reason = iota
+    // UnusedLet statements bind a value nobody reads.
+    UnusedLet
+)
+
+var reasonNames = [...]string{
+    Unreachable: "unreachable statement",
+    UnusedLet:   "unused binding",
+}
+
+func (r Reason) String() string {
+    return reasonNames[r]
+}
+
+// A Removal is a statement removed by EliminateDeadCode.
+type Removal struct {
+    Statement   ast.Statement
+    Reason      Reason
+}
+
+func (r Removal) String() string {
+    what := r.Statement.String()
+    if let, ok := r.Statement.(*ast.LetStatement); ok && let.Name != nil {
+        what = let.Name.Value
+    }
+    return ast.Pos(r.Statement).String() + ": removed " + r.Reason.String() + " " + what
+}
+
+// EliminateDeadCode removes from program, in place, the statements that
+// follow a return statement in the same block, and let statements whose
+// binding is never read and whose value has no effect when evaluated.
+// It returns what it removed, in source order.
+//
+// A value has no effect if it is a literal, a function literal or a
+// defined name; anything else, such as a call or an operator that may
+// fail at run time, is kept. A let statement that ends a block or the
+// program is kept too, since it determines the block's or the
+// program's value. Removing a binding may leave others unused, so the
+// pass repeats until nothing changes.
+func EliminateDeadCode(program *ast.Program) []Removal {
+    removed := []Removal{}
+
+    ast.Inspect(program, func(n ast.Node) bool {
+        switch n := n.(type) {
+        case *ast.Program:
+            n.Statements = truncateAfterReturn(n.Statements, &removed)
+        case *ast.BlockStatement:
+            n.Statements = truncateAfterReturn(n.Statements, &removed)
+        }
+        return true
+    })
+
+    for {
+        unused := unusedLets(program)
+        if len(unused) == 0 {
+            break
+        }
+
+        astutil.Apply(program, func(c *astutil.Cursor) bool {
+            if let, ok := c.Node().(*ast.LetStatement); ok && unused[let] {
+                removed = append(removed, Removal{Statement: let, Reason: UnusedLet})
+                c.Delete()
+                return false
+            }
+            return true
+        }, nil)
+    }
+
+    sort.SliceStable(removed, func(i, j int) bool {
+        return ast.Pos(removed[i].Statement).Offset < ast.Pos(removed[j].Statement).Offset
+    })
+
+    return removed
+}
+
+func truncateAfterReturn(list []ast.Statement, removed *[]Removal) []ast.Statement {
+    for i, s := range list {
+        if _, ok := s.(*ast.ReturnStatement); ok {
+            for _, dead := range list[i+1:] {
+                *removed = append(*removed, Removal{Statement: dead, Reason: Unreachable})
+            }
+            return list[:i+1]
+        }
+    }
+    return list
+}
+
+// unusedLets returns the removable let statements of program.
+func unusedLets(program *ast.Program) map[*ast.LetStatement]bool {
+    info, _ := resolve.Resolve(program)
+
+    uses := map[*resolve.Object][]*ast.Identifier{}
+    for id, obj := range info.Uses {
+        uses[obj] = append(uses[obj], id)
+    }
+
+    // let statements that end a block or the program
+    last := map[ast.Statement]bool{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        var list []ast.Statement
+        switch n := n.(type) {
+        case *ast.Program:
+            list = n.Statements
+        case *ast.BlockStatement:
+            list = n.Statements
+        }
+        if len(list) > 0 {
+            last[list[len(list)-1]] = true
+        }
+        return true
+    })
+
+    unused := map[*ast.LetStatement]bool{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        let, ok := n.(*ast.LetStatement)
+        if !ok || let.Name == nil || last[let] || !pure(let.Value, info) {
+            return true
+        }
+        obj := info.Defs[let.Name]
+        if obj == nil {
+            return true
+        }
+
+        inside := map[ast.Node]bool{}
+        ast.Inspect(let.Value, func(n ast.Node) bool {
+            inside[n] = true
+            return true
+        })
+        for _, id := range uses[obj] {
+            if !inside[id] {
+                return true
+            }
+        }
+
+        unused[let] = true
+        return true
+    })
+
+    return unused
+}
+
+// pure reports whether evaluating e has no effect and cannot fail.
+func pure(e ast.Expression, info *resolve.Info) bool {
+    switch e := e.(type) {
+    case *ast.IntegerLiteral, *ast.Boolean, *ast.NullLiteral, *ast.FunctionLiteral:
+        return true
+    case *ast.Identifier:
+        return info.Uses[e] != nil
+    case *ast.PrefixExpression:
+        _, ok := intValue(e)
+        return ok
+    }
+    return false
+}
@@ -0,0 +1,322 @@
+// Package optimize contains passes that simplify Monkey programs
+// without changing what they compute.
+package optimize
+
+import (
+	"math"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/astutil"
+	"github.com/UsamaHameed/monkey-interpreter/ast/build"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Fold rewrites program in place, evaluating operators whose operands
+// are literals, removing identities such as `(a + b) * 1` on operands
+// that are provably integers, and replacing if expressions whose
+// condition is a literal by the branch that is taken. It returns
+// program.
+//
+// Expressions whose evaluation fails at run time, such as division by
+// zero or arithmetic that overflows int64, are left alone so that the
+// failure is preserved. Negative results are written as `-n`, a prefix
+// expression, since Monkey has no negative literals.
+func Fold(program *ast.Program) *ast.Program {
+    // the body of an arrow function written without a block must stay a
+    // single expression
+    implicit := map[ast.Node]bool{}
+    ast.Inspect(program, func(n ast.Node) bool {
+        if fl, ok := n.(*ast.FunctionLiteral); ok && fl.ImplicitReturn {
+            implicit[fl.Body] = true
+        }
+        return true
+    })
+
+    astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+        switch n := c.Node().(type) {
+        case *ast.PrefixExpression:
+            if e := foldPrefix(n); e != nil {
+                c.Replace(e)
+            }
+
+        case *ast.InfixExpression:
+            if e := foldInfix(n); e != nil {
+                c.Replace(e)
+            }
+
+        case *ast.IfExpression:
+            if e := foldIf(n); e != nil {
+                c.Replace(e)
+            }
+
+        case *ast.ExpressionStatement:
+            // a taken branch of several statements can only replace an
+            // if expression that is a statement of its own
+            if list := foldIfStatement(n); list != nil && c.Index() >= 0 && !implicit[c.Parent()] {
+                for _, s := range list {
+                    c.InsertBefore(s)
+                }
+                c.Delete()
+            }
+        }
+        return true
+    })
+
+    return program
+}
+
+// intValue returns the value of an integer literal or a negated one.
+func intValue(e ast.Expression) (int64, bool) {
+    switch e := e.(type) {
+    case *ast.IntegerLiteral:
+        return e.Value, true
+    case *ast.PrefixExpression:
+        if lit, ok := e.Right.(*ast.IntegerLiteral); ok && e.Operator == "-" {
+            return -lit.Value, true
+        }
+    }
+    return 0, false
+}
+
+func boolValue(e ast.Expression) (bool, bool) {
+    if b, ok := e.(*ast.Boolean); ok {
+        return b.Value, true
+    }
+    return false, false
+}
+
+// truthy returns whether the literal e counts as true. In Monkey null
+// and false are false and every integer is true.
+func truthy(e ast.Expression) (bool, bool) {
+    if _, ok := intValue(e); ok {
+        return true, true
+    }
+    if _, ok := e.(*ast.NullLiteral); ok {
+        return false, true
+    }
+    return boolValue(e)
+}
+
+func isLiteral(e ast.Expression) bool {
+    _, ok := truthy(e)
+    return ok
+}
+
+// intLiteral returns v as a literal at pos. v must not be math.MinInt64.
+func intLiteral(v int64, pos token.Position) ast.Expression {
+    if v < 0 {
+        lit := build.Int(-v)
+        lit.Token.Pos = pos
+        lit.Token.Pos.Offset++
+        lit.Token.Pos.Column++
+
+        e := build.Prefix("-", lit)
+        e.Token.Pos = pos
+        return e
+    }
+
+    lit := build.Int(v)
+    lit.Token.Pos = pos
+    return lit
+}
+
+func boolLiteral(v bool, pos token.Position) ast.Expression {
+    b := build.Bool(v)
+    b.Token.Pos = pos
+    return b
+}
+
+func foldPrefix(e *ast.PrefixExpression) ast.Expression {
+    switch e.Operator {
+    case "-":
+        if _, ok := e.Right.(*ast.IntegerLiteral); ok {
+            return nil
+        }
+        if v, ok := intValue(e.Right); ok {
+            return intLiteral(-v, e.Token.Pos)
+        }
+
+    case "!":
+        if v, ok := truthy(e.Right); ok {
+            return boolLiteral(!v, e.Token.Pos)
+        }
+    }
+    return nil
+}
+
+func foldInfix(e *ast.InfixExpression) ast.Expression {
+    pos := ast.Pos(e)
+
+    if e.Operator == "??" {
+        if _, ok := e.Left.(*ast.NullLiteral); ok {
+            return e.Right
+        }
+        if isLiteral(e.Left) {
+            return e.Left
+        }
+        return nil
+    }
+
+    a, aok := intValue(e.Left)
+    b, bok := intValue(e.Right)
+
+    if aok && bok {
+        switch e.Operator {
+        case "<":
+            return boolLiteral(a < b, pos)
+        case ">":
+            return boolLiteral(a > b, pos)
+        case "==":
+            return boolLiteral(a == b, pos)
+        case "!=":
+            return boolLiteral(a != b, pos)
+        }
+
+        if v, ok := arith(e.Operator, a, b); ok {
+            return intLiteral(v, pos)
+        }
+        return nil
+    }
+
+    if x, ok := boolValue(e.Left); ok {
+        if y, ok := boolValue(e.Right); ok {
+            switch e.Operator {
+            case "==":
+                return boolLiteral(x == y, pos)
+            case "!=":
+                return boolLiteral(x != y, pos)
+            }
+        }
+        return nil
+    }
+
+    // identities, for operands that are not both literals; the operand
+    // kept must be an integer, or the operator would fail
+    switch {
+    case bok && b == 1 && (e.Operator == "*" || e.Operator == "/") && isInt(e.Left):
+        return e.Left
+    case bok && b == 0 && (e.Operator == "+" || e.Operator == "-") && isInt(e.Left):
+        return e.Left
+    case aok && a == 1 && e.Operator == "*" && isInt(e.Right):
+        return e.Right
+    case aok && a == 0 && e.Operator == "+" && isInt(e.Right):
+        return e.Right
+    }
+
+    return nil
+}
+
+// isInt reports whether e is provably an integer: an integer literal,
+// its negation, or an arithmetic operation, which fails at run time on
+// its own if its value is not an integer. Identifiers and calls may hold
+// any value, so an identity on them is kept.
+func isInt(e ast.Expression) bool {
+    switch e := e.(type) {
+    case *ast.IntegerLiteral:
+        return true
+    case *ast.PrefixExpression:
+        return e.Operator == "-" && isInt(e.Right)
+    case *ast.InfixExpression:
+        switch e.Operator {
+        case "+", "-", "*", "/":
+            return true
+        }
+    }
+    return false
+}
+
+// arith evaluates a op b, reporting false if the operation fails at
+// run time or its result has no literal form.
+func arith(op string, a, b int64) (int64, bool) {
+    var v int64
+
+    switch op {
+    case "+":
+        if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+            return 0, false
+        }
+        v = a + b
+    case "-":
+        if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+            return 0, false
+        }
+        v = a - b
+    case "*":
+        v = a * b
+        if a != 0 && (v/a != b || (a == -1 && b == math.MinInt64)) {
+            return 0, false
+        }
+    case "/":
+        if b == 0 || (a == math.MinInt64 && b == -1) {
+            return 0, false
+        }
+        v = a / b
+    default:
+        return 0, false
+    }
+
+    if v == math.MinInt64 {
+        return 0, false
+    }
+    return v, true
+}
+
+// branch returns the block an if expression with a literal condition
+// takes, or nil if it takes none; ok is false if the condition is not
+// a literal.
+func branch(e *ast.IfExpression) (block *ast.BlockStatement, ok bool) {
+    v, ok := truthy(e.Condition)
+    if !ok {
+        return nil, false
+    }
+    if v {
+        return e.Consequence, true
+    }
+    return e.Alternative, true
+}
+
+// foldIf replaces an if expression whose taken branch is empty or a
+// single expression by the value of that branch.
+func foldIf(e *ast.IfExpression) ast.Expression {
+    block, ok := branch(e)
+    if !ok {
+        return nil
+    }
+
+    if block == nil || len(block.Statements) == 0 {
+        null := build.Null()
+        null.Token.Pos = e.Token.Pos
+        return null
+    }
+
+    if len(block.Statements) == 1 {
+        if es, ok := block.Statements[0].(*ast.ExpressionStatement); ok && es.Expression != nil {
+            return es.Expression
+        }
+    }
+
+    return nil
+}
+
+// foldIfStatement returns the statements of the taken branch of an if
+// expression statement, which then replace it. Branches that declare
+// bindings are kept in their block.
+func foldIfStatement(s *ast.ExpressionStatement) []ast.Statement {
+    e, ok := s.Expression.(*ast.IfExpression)
+    if !ok {
+        return nil
+    }
+
+    block, ok := branch(e)
+    if !ok || block == nil || len(block.Statements) == 0 {
+        return nil
+    }
+
+    for _, s := range block.Statements {
+        if _, ok := s.(*ast.LetStatement); ok {
+            return nil
+        }
+    }
+
+    return block.Statements
+}
@@ -0,0 +1,210 @@
+package optimize
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/format"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// golden runs pass over each testdata/dir/*.mk file and compares the
+// formatted result with the matching .golden file.
+func golden(t *testing.T, dir string, pass func(*ast.Program) string) {
+    inputs, err := filepath.Glob(filepath.Join("testdata", dir, "*.mk"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(inputs) == 0 {
+        t.Fatalf("no inputs in testdata/%s", dir)
+    }
+
+    for _, input := range inputs {
+        src, err := os.ReadFile(input)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        got := pass(testutil.Parse(t, string(src)))
+
+        name := strings.TrimSuffix(input, ".mk") + ".golden"
+        if *update {
+            if err := os.WriteFile(name, []byte(got), 0644); err != nil {
+                t.Fatal(err)
+            }
+            continue
+        }
+
+        expected, err := os.ReadFile(name)
+        if err != nil {
+            t.Fatalf("%s (run go test -update to create it)", err)
+        }
+        if got != string(expected) {
+            t.Errorf("%s: result does not match %s (run go test -update to accept).\nexpected=\n%s\ngot=\n%s",
+                input, name, expected, got)
+        }
+    }
+}
+
+func TestFoldGolden(t *testing.T) {
+    golden(t, "fold", func(program *ast.Program) string {
+        return format.String(Fold(program))
+    })
+}
+
+func TestFoldIdempotent(t *testing.T) {
+    for _, input := range corpus.Programs {
+        once := Fold(testutil.Parse(t, input))
+        twice := Fold(testutil.Parse(t, format.String(once)))
+
+        if format.String(once) != format.String(twice) {
+            t.Errorf("folding %q twice changed it again.\nonce= %q\ntwice=%q",
+                input, format.String(once), format.String(twice))
+        }
+    }
+}
+
+func TestArith(t *testing.T) {
+    tests := []struct {
+        op          string
+        a, b        int64
+        expected    int64
+        ok          bool
+    }{
+        {"+", 1, 2, 3, true},
+        {"+", 1<<62, 1<<62, 0, false},
+        {"-", -1 << 62, 1<<62 + 1, 0, false},
+        {"-", 0, 1<<63 - 1, -(1<<63 - 1), true},
+        {"*", -3, 3, -9, true},
+        {"*", 1 << 32, 1 << 32, 0, false},
+        {"*", -1, -1 << 63, 0, false},
+        {"/", 7, -2, -3, true},
+        {"/", 1, 0, 0, false},
+        {"/", -1 << 63, -1, 0, false},
+        {"-", -1<<63 + 1, 1, 0, false},
+        {"<", 1, 2, 0, false},
+    }
+
+    for _, tt := range tests {
+        v, ok := arith(tt.op, tt.a, tt.b)
+        if ok != tt.ok || (ok && v != tt.expected) {
+            t.Errorf("arith(%q, %d, %d) = %d, %t; expected %d, %t", tt.op, tt.a, tt.b, v, ok, tt.expected, tt.ok)
+        }
+    }
+}
+
+func TestEliminateDeadCodeGolden(t *testing.T) {
+    golden(t, "deadcode", func(program *ast.Program) string {
+        removed := EliminateDeadCode(program)
+
+        var out strings.Builder
+        out.WriteString(format.String(program))
+        out.WriteString("\n// removed:\n")
+        for _, r := range removed {
+            out.WriteString("// " + r.String() + "\n")
+        }
+        return out.String()
+    })
+}
+
+func TestEliminateDeadCodeAfterFold(t *testing.T) {
+    program := testutil.Parse(t, "let debug = 1 > 2; let f = fn() { return 1 + 1; 3 }; f();")
+
+    removed := EliminateDeadCode(Fold(program))
+
+    expected := "let f = fn() {\n    return 2;\n};\nf();\n"
+    if got := format.String(program); got != expected {
+        t.Errorf("wrong program.\nexpected=%q\ngot=     %q", expected, got)
+    }
+
+    if len(removed) != 2 || removed[0].Reason != UnusedLet || removed[1].Reason != Unreachable {
+        t.Errorf("wrong removals: %v", removed)
+    }
+}
+
+func TestMarkTailCalls(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {"f(1)", []string{}},
+        {"return f(1);", []string{}},
+        {"fn(n) { f(n) }", []string{"f(n)"}},
+        {"fn(n) { f(n); g(n) }", []string{"g(n)"}},
+        {"fn(n) { return f(n); }", []string{"f(n)"}},
+        {"fn(n) { 1 + f(n) }", []string{}},
+        {"fn(n) { f(g(n)) }", []string{"f(g(n))"}},
+        {"fn(n) { let x = f(n); }", []string{}},
+        {"fn(n) { if (n) { f(n) } else { g(n) } }", []string{"f(n)", "g(n)"}},
+        {"fn(n) { if (n) { f(n) } else { g(n) }; 1 }", []string{}},
+        {"fn(n) { if (n) { return f(n); } g(n) }", []string{"f(n)", "g(n)"}},
+        {"fn(n) { if (n) { if (g(n)) { f(n) } } }", []string{"f(n)"}},
+        {"fn(n) { return if (n) { f(n) } else { 0 }; }", []string{"f(n)"}},
+        {"fn(n) { n |> f }", []string{}},
+        {"fn(n) { n |> f(1) }", []string{}},
+        {"n => f(n)", []string{"f(n)"}},
+        {"fn(n) { fn() { g(n) }; f(n) }", []string{"g(n)", "f(n)"}},
+        {"fn(n) { h(fn() { return g(n); }) }", []string{"h(fn() { return g(n); })", "g(n)"}},
+    }
+
+    for _, tt := range tests {
+        program := testutil.Parse(t, tt.input)
+
+        got := []string{}
+        for _, call := range MarkTailCalls(program) {
+            got = append(got, call.String())
+        }
+        if strings.Join(got, " | ") != strings.Join(tt.expected, " | ") {
+            t.Errorf("%q: wrong tail calls.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+
+        ast.Inspect(program, func(n ast.Node) bool {
+            if call, ok := n.(*ast.CallExpression); ok {
+                marked := false
+                for _, s := range got {
+                    marked = marked || s == call.String()
+                }
+                if call.IsTail != marked {
+                    t.Errorf("%q: %s has IsTail=%t", tt.input, call, call.IsTail)
+                }
+            }
+            return true
+        })
+    }
+}
+
+func TestMarkTailCallsClearsStaleFlags(t *testing.T) {
+    program := testutil.Parse(t, "let f = fn(n) { g(n) };")
+    MarkTailCalls(program)
+
+    // the call is no longer the value of the body once a statement
+    // follows it
+    body := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral).Body
+    call := body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+    body.Statements = append(body.Statements, &ast.ExpressionStatement{Expression: &ast.IntegerLiteral{Value: 1}})
+
+    if marked := MarkTailCalls(program); len(marked) != 0 || call.IsTail {
+        t.Errorf("stale tail call: %v, IsTail=%t", marked, call.IsTail)
+    }
+}
+
+func TestFoldArrowBody(t *testing.T) {
+    program := Fold(testutil.Parse(t, "let f = x => if (true) { a; b };"))
+
+    fl := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+    if !fl.ImplicitReturn || len(fl.Body.Statements) != 1 {
+        t.Fatalf("arrow body was spliced: %s", fl)
+    }
+
+    expected := "let f = x => if (true) {\n    a;\n    b;\n};\n"
+    if got := format.String(program); got != expected {
+        t.Errorf("wrong program.\nexpected=%q\ngot=     %q", expected, got)
+    }
+}
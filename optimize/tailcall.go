@@ -0,0 +1,98 @@
+package optimize
+
+import (
+	"sort"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+)
+
+// MarkTailCalls sets IsTail on every call in program that is in tail
+// position, and clears it on every other call. It returns the calls it
+// marked, in source order.
+//
+// A call is in tail position if the enclosing function returns its value
+// as is: it is the value of a return statement, the last expression of
+// the function's body, or in tail position in either branch of an if
+// expression that is. Calls at the top level of the program are never
+// in tail position, and neither are the calls pipes make, which have no
+// CallExpression of their own.
+func MarkTailCalls(program *ast.Program) []*ast.CallExpression {
+    tail := TailCalls(program)
+
+    ast.Inspect(program, func(n ast.Node) bool {
+        if call, ok := n.(*ast.CallExpression); ok {
+            call.IsTail = tail[call]
+        }
+        return true
+    })
+
+    marked := []*ast.CallExpression{}
+    for call := range tail {
+        marked = append(marked, call)
+    }
+    sort.Slice(marked, func(i, j int) bool {
+        return ast.Pos(marked[i]).Offset < ast.Pos(marked[j]).Offset
+    })
+
+    return marked
+}
+
+// TailCalls returns the calls in program that are in tail position,
+// without marking them.
+func TailCalls(program *ast.Program) map[*ast.CallExpression]bool {
+    tail := map[*ast.CallExpression]bool{}
+
+    ast.Inspect(program, func(n ast.Node) bool {
+        fl, ok := n.(*ast.FunctionLiteral)
+        if !ok || fl.Body == nil {
+            return true
+        }
+
+        tailStatements(fl.Body.Statements, tail)
+
+        // return statements anywhere in the body, but not in the
+        // function literals it contains, which are visited on their own
+        ast.Inspect(fl.Body, func(n ast.Node) bool {
+            switch n := n.(type) {
+            case *ast.FunctionLiteral:
+                return false
+            case *ast.ReturnStatement:
+                tailExpression(n.ReturnValue, tail)
+            }
+            return true
+        })
+        return true
+    })
+
+    return tail
+}
+
+// tailStatements adds the tail calls of a block whose value is returned.
+func tailStatements(list []ast.Statement, tail map[*ast.CallExpression]bool) {
+    if len(list) == 0 {
+        return
+    }
+    switch s := list[len(list)-1].(type) {
+    case *ast.ExpressionStatement:
+        tailExpression(s.Expression, tail)
+    case *ast.ReturnStatement:
+        tailExpression(s.ReturnValue, tail)
+    }
+}
+
+// tailExpression adds the tail calls of e, whose value is returned.
+func tailExpression(e ast.Expression, tail map[*ast.CallExpression]bool) {
+    switch e := e.(type) {
+    case *ast.CallExpression:
+        tail[e] = true
+    case *ast.IfExpression:
+        if e.Consequence != nil {
+            tailStatements(e.Consequence.Statements, tail)
+        }
+        if e.Alternative != nil {
+            tailStatements(e.Alternative.Statements, tail)
+        }
+    case *ast.BlockStatement:
+        tailStatements(e.Statements, tail)
+    }
+}
@@ -0,0 +1,6 @@
+let unused = 1;
+let f = fn() {
+    let inner = 2;
+};
+f();
+let last = 3;
@@ -0,0 +1,4 @@
+let x = 1;
+return x;
+let y = 2;
+y;
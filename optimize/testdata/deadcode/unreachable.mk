@@ -0,0 +1,13 @@
+let f = fn(x) {
+    return x;
+    puts(x);
+    x + 1;
+};
+let g = fn(x) {
+    if (x) {
+        return 1;
+        2;
+    }
+    return 3;
+};
+f(g(1));
@@ -0,0 +1,17 @@
+let a = 1;
+let b = a;
+let c = fn(x) { x };
+let d = f(1);
+let e = undefined;
+let h = 5;
+let unusedInner = fn() {
+    let t = 1;
+    let u = 2;
+    u;
+};
+let used = fn(n) {
+    let k = n;
+    let last = 2;
+};
+let rec = fn(n) { rec(n) };
+used(h);
@@ -0,0 +1,7 @@
+let a = 1 + 2 * 3;
+let b = (10 - 4) / 3;
+let c = 2 - 5;
+let d = -(3 - 10);
+let e = --7;
+let f = 7 / 2 + -7 / 2;
+let g = x * (2 + 3);
@@ -0,0 +1,11 @@
+let a = !!true;
+let b = 1 < 2;
+let c = 3 == 4;
+let d = true != false;
+let e = !5;
+let f = !null;
+let g = (1 + 1 == 2) == true;
+let h = null ?? 5;
+let i = 0 ?? x;
+let j = x ?? 1;
+let k = 1 == true;
@@ -0,0 +1,13 @@
+let a = if (true) { 1 } else { 2 };
+let b = if (false) { 1 } else { 2 };
+let c = if (false) { 1 };
+let d = if (1 > 2) { x } else { y + 0 };
+let e = fn(n) {
+    if (true) {
+        puts(n);
+        return n;
+    }
+};
+if (!true) { log(1) } else { log(2); log(3) }
+if (true) { let z = 1; z }
+if (x) { 1 + 1 } else { 2 * 2 }
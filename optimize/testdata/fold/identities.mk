@@ -0,0 +1,15 @@
+let a = x * 1;
+let b = 1 * x;
+let c = x + 0;
+let d = 0 + x;
+let e = x - 0;
+let f = x / 1;
+let g = f(y) * (2 - 1);
+let h = 0 - x;
+let i = x * 0;
+let j = (x + y) * 1;
+let k = 0 + a * b;
+let l = (x - y) / 1;
+let m = -(x * 2) - 0;
+let n = 1 * -(2 * x);
+let o = -x + 0;
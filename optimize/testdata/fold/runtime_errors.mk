@@ -0,0 +1,18 @@
+let a = 1 / 0;
+let b = 10 / (5 - 5);
+let c = 9223372036854775807 + 1;
+let d = -9223372036854775807 - 2;
+let e = 4611686018427387904 * 2;
+let f = -9223372036854775807 - 1;
+let g = 9223372036854775807 + 0;
+let h = 3 * -3;
+let i = 1 * true;
+let j = 0 + null;
+let k = null - 0;
+let l = false / 1;
+let m = fn() { 1 } * 1;
+let n = true;
+let o = fn() { 1 };
+n * 1;
+o + 0;
+let p = n - 0;
@@ -19,6 +19,7 @@ type Parser struct {
     curToken    token.Token
     peekToken   token.Token
     errors      []string
+    errorList   []Error
 
     prefixParseFns   map[token.TokenType]prefixParseFn
     infixParseFns    map[token.TokenType]infixParseFn
@@ -28,7 +29,9 @@ type Parser struct {
 const (
     _ int = iota
     LOWEST
+    PIPE        // x |> f(y)
     EQUALS      // ==
+    COALESCE    // a ?? b
     LESSGREATER // > or <
     SUM         // +
     PRODUCT     // *
@@ -37,8 +40,10 @@ const (
 )
 
 var precedencesMap = map[token.TokenType]int {
+    token.PIPE:     PIPE,
     token.EQ:       EQUALS,
     token.UNEQ:     EQUALS,
+    token.NULLISH:  COALESCE,
     token.LT:       LESSGREATER,
     token.GT:       LESSGREATER,
     token.PLUS:     SUM,
@@ -46,20 +51,26 @@ var precedencesMap = map[token.TokenType]int {
     token.SLASH:    PRODUCT,
     token.ASTERISK: PRODUCT,
     token.LPAREN:   CALL,
+    token.DOT:      CALL,
+    token.OPTDOT:   CALL,
 }
 
-func (p *Parser) peekPrecedence() int {
-    if p, ok := precedencesMap[p.peekToken.Type]; ok {
+// Precedence returns the precedence of t as an infix operator, or
+// LOWEST if t is not one. Other parsers of Monkey, such as the one in
+// package cst, use it so that they group operators the same way.
+func Precedence(t token.TokenType) int {
+    if p, ok := precedencesMap[t]; ok {
         return p
     }
     return LOWEST
 }
 
+func (p *Parser) peekPrecedence() int {
+    return Precedence(p.peekToken.Type)
+}
+
 func (p *Parser) curPrecedence() int {
-    if p, ok := precedencesMap[p.curToken.Type]; ok {
-        return p
-    }
-    return LOWEST
+    return Precedence(p.curToken.Type)
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -75,9 +86,11 @@ func New(l *lexer.Lexer) *Parser {
     p.registerPrefix(token.MINUS, p.parsePrefixExpression)
     p.registerPrefix(token.TRUE, p.parseBoolean)
     p.registerPrefix(token.FALSE, p.parseBoolean)
+    p.registerPrefix(token.NULL, p.parseNull)
     p.registerPrefix(token.LPAREN, p.parseGroupedExpession)
     p.registerPrefix(token.IF, p.parseIfExpression)
     p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+    p.registerPrefix(token.PLACEHOLDER, p.parsePlaceholder)
 
     p.infixParseFns = make(map[token.TokenType]infixParseFn)
     p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -88,7 +101,11 @@ func New(l *lexer.Lexer) *Parser {
     p.registerInfix(token.UNEQ, p.parseInfixExpression)
     p.registerInfix(token.LT, p.parseInfixExpression)
     p.registerInfix(token.GT, p.parseInfixExpression)
+    p.registerInfix(token.NULLISH, p.parseInfixExpression)
     p.registerInfix(token.LPAREN, p.parseCallExpression)
+    p.registerInfix(token.PIPE, p.parsePipeExpression)
+    p.registerInfix(token.DOT, p.parseMemberExpression)
+    p.registerInfix(token.OPTDOT, p.parseMemberExpression)
 
     p.nextToken()
     p.nextToken()
@@ -114,7 +131,7 @@ func (p *Parser) parseStatement() ast.Statement {
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
     msg := fmt.Sprintf("no prefix parse function for %s found", t)
-    p.errors = append(p.errors, msg)
+    p.error(p.curToken.Pos, msg)
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
@@ -165,6 +182,16 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
     return e
 }
 
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+    e := &ast.PipeExpression{Token: p.curToken, Left: left}
+
+    precedence := p.curPrecedence()
+    p.nextToken()
+    e.Right = p.parseExpression(precedence)
+
+    return e
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
     s := &ast.ExpressionStatement{Token: p.curToken}
 
@@ -184,7 +211,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
     s.ReturnValue = p.parseExpression(LOWEST)
 
-    if !p.curTokenIs(token.SEMICOLON) {
+    if p.peekTokenIs(token.SEMICOLON) {
         p.nextToken()
     }
 
@@ -194,12 +221,18 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
     s := &ast.LetStatement{Token: p.curToken}
 
-    if !p.expectPeek(token.IDENT) {
+    if !p.expectIdent() {
         return nil
     }
 
     s.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+    if p.peekTokenIs(token.COLON) {
+        if s.Name.Annotation = p.parseAnnotation(); s.Name.Annotation == nil {
+            return nil
+        }
+    }
+
     if !p.expectPeek(token.ASSIGN) {
         return nil
     }
@@ -207,7 +240,7 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
     s.Value = p.parseExpression(LOWEST)
 
-    for !p.curTokenIs(token.SEMICOLON) {
+    if p.peekTokenIs(token.SEMICOLON) {
         p.nextToken()
     }
 
@@ -220,7 +253,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
     value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
     if err != nil {
         msg := fmt.Sprintf("could not parse %q as an int", p.curToken.Literal)
-        p.errors = append(p.errors, msg)
+        p.error(p.curToken.Pos, msg)
 
         return nil
     }
@@ -233,15 +266,116 @@ func (p *Parser) parseBoolean() ast.Expression {
     return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
+func (p *Parser) parseNull() ast.Expression {
+    return &ast.NullLiteral{Token: p.curToken}
+}
+
+// parseGroupedExpession also covers the parameter list of an arrow
+// function: the parenthesized list is parsed as expressions first and
+// reinterpreted as parameters if it turns out to be followed by `=>`.
+// An identifier in the list may carry a type annotation, as in
+// `(x: int) => x`; the list must then be followed by `=>`. The
+// unparenthesized form `x => x` has no room for one.
 func (p *Parser) parseGroupedExpession() ast.Expression {
+    if p.peekTokenIs(token.RPAREN) {
+        p.nextToken()
+
+        if !p.expectPeek(token.ARROW) {
+            return nil
+        }
+        return p.parseArrowFunction([]*ast.Identifier{})
+    }
+
     p.nextToken()
 
-    expression := p.parseExpression(LOWEST)
+    annotated := false
+    expressions := []ast.Expression{p.parseGroupedElement(&annotated)}
+
+    for p.peekTokenIs(token.COMMA) {
+        p.nextToken()
+        p.nextToken()
+        expressions = append(expressions, p.parseGroupedElement(&annotated))
+    }
 
     if !p.expectPeek(token.RPAREN) {
         return nil
     }
-    return expression
+
+    if !p.peekTokenIs(token.ARROW) {
+        if len(expressions) > 1 || annotated {
+            p.peekError(token.ARROW)
+            return nil
+        }
+        return expressions[0]
+    }
+
+    params := []*ast.Identifier{}
+    for _, e := range expressions {
+        if ph, ok := e.(*ast.Placeholder); ok {
+            e = &ast.Identifier{Token: ph.Token, Value: ph.Token.Literal}
+        }
+        ident, ok := e.(*ast.Identifier)
+        if !ok || ident == nil {
+            msg := fmt.Sprintf("invalid arrow function parameter %T", e)
+            p.error(ast.Pos(e), msg)
+            return nil
+        }
+        params = append(params, ident)
+    }
+
+    p.nextToken()
+    return p.parseArrowFunction(params)
+}
+
+// parseGroupedElement parses an expression in a parenthesized list,
+// followed by a type annotation if it is an identifier and the next
+// token is a colon; annotated is then set.
+func (p *Parser) parseGroupedElement(annotated *bool) ast.Expression {
+    e := p.parseExpression(LOWEST)
+    if !p.peekTokenIs(token.COLON) {
+        return e
+    }
+
+    var ident *ast.Identifier
+    switch e := e.(type) {
+    case *ast.Identifier:
+        ident = e
+    case *ast.Placeholder:
+        ident = &ast.Identifier{Token: e.Token, Value: e.Token.Literal}
+    default:
+        return e
+    }
+
+    *annotated = true
+    if ident.Annotation = p.parseAnnotation(); ident.Annotation == nil {
+        return nil
+    }
+    return ident
+}
+
+// parseArrowFunction is called with the `=>` token as curToken.
+func (p *Parser) parseArrowFunction(params []*ast.Identifier) ast.Expression {
+    fnLiteral := &ast.FunctionLiteral{Token: p.curToken, Parameters: params}
+
+    if p.peekTokenIs(token.LBRACE) {
+        p.nextToken()
+        fnLiteral.Body = p.parseBlockStatement()
+
+        return fnLiteral
+    }
+
+    p.nextToken()
+
+    body := &ast.ExpressionStatement{Token: p.curToken}
+    body.Expression = p.parseExpression(LOWEST)
+
+    fnLiteral.Body = &ast.BlockStatement{
+        Token:      body.Token,
+        Statements: []ast.Statement{body},
+    }
+    fnLiteral.ImplicitReturn = true
+
+    return fnLiteral
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
@@ -287,6 +421,14 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
     fnLiteral.Parameters = p.parseFunctionParameters()
 
+    if p.peekTokenIs(token.THINARROW) {
+        p.nextToken()
+        p.nextToken()
+        if fnLiteral.Result = p.parseTypeExpr(); fnLiteral.Result == nil {
+            return nil
+        }
+    }
+
     if !p.expectPeek(token.LBRACE) {
         return nil
     }
@@ -306,14 +448,20 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
     p.nextToken()
 
-    identifier := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+    identifier := p.parseFunctionParameter()
+    if identifier == nil {
+        return nil
+    }
     identifiers = append(identifiers, identifier)
 
     for p.peekTokenIs(token.COMMA) {
         p.nextToken()
         p.nextToken()
 
-        identifier := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+        identifier := p.parseFunctionParameter()
+        if identifier == nil {
+            return nil
+        }
         identifiers = append(identifiers, identifier)
     }
 
@@ -324,6 +472,18 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
     return identifiers
 }
 
+func (p *Parser) parseFunctionParameter() *ast.Identifier {
+    identifier := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+    if p.peekTokenIs(token.COLON) {
+        if identifier.Annotation = p.parseAnnotation(); identifier.Annotation == nil {
+            return nil
+        }
+    }
+
+    return identifier
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
     call := &ast.CallExpression{Token: p.curToken, Function: function}
     call.Arguments = p.parseCallArguments()
@@ -331,6 +491,22 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
     return call
 }
 
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+    member := &ast.MemberExpression{
+        Token:      p.curToken,
+        Object:     object,
+        Optional:   p.curTokenIs(token.OPTDOT),
+    }
+
+    if !p.expectIdent() {
+        return nil
+    }
+
+    member.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+    return member
+}
+
 func (p *Parser) parseCallArguments() []ast.Expression {
     args := []ast.Expression{}
 
@@ -390,13 +566,43 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
     }
 }
 
+// expectIdent is expectPeek(token.IDENT), but also accepts a template
+// placeholder, which then stands for the identifier.
+func (p *Parser) expectIdent() bool {
+    if p.peekTokenIs(token.PLACEHOLDER) {
+        p.nextToken()
+        return true
+    }
+    return p.expectPeek(token.IDENT)
+}
+
 func (p *Parser) Errors() []string {
     return p.errors
 }
 
+// An Error is a parse error at the position of the offending token.
+type Error struct {
+    Pos token.Position
+    Msg string
+}
+
+func (e Error) Error() string {
+    return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ErrorList returns the same errors as Errors, with their positions.
+func (p *Parser) ErrorList() []Error {
+    return p.errorList
+}
+
+func (p *Parser) error(pos token.Position, msg string) {
+    p.errors = append(p.errors, msg)
+    p.errorList = append(p.errorList, Error{Pos: pos, Msg: msg})
+}
+
 func (p *Parser) peekError(t token.TokenType) {
     msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-    p.errors = append(p.errors, msg)
+    p.error(p.peekToken.Pos, msg)
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -423,6 +629,107 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-    return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+    identifier := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+    if p.peekTokenIs(token.ARROW) {
+        p.nextToken()
+        return p.parseArrowFunction([]*ast.Identifier{identifier})
+    }
+
+    return identifier
+}
+
+
+func (p *Parser) parsePlaceholder() ast.Expression {
+    if p.peekTokenIs(token.ARROW) {
+        identifier := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+        p.nextToken()
+        return p.parseArrowFunction([]*ast.Identifier{identifier})
+    }
+
+    return &ast.Placeholder{Token: p.curToken, Name: p.curToken.Literal[1:]}
+}
+
+// parseAnnotation is called with the `:` as peekToken and returns the
+// type that follows it.
+func (p *Parser) parseAnnotation() ast.TypeExpr {
+    p.nextToken()
+    p.nextToken()
+
+    return p.parseTypeExpr()
+}
+
+// parseTypeExpr parses `name`, `name<T, ...>` or `fn(T, ...) -> T`,
+// starting at curToken. It returns nil after recording an error.
+func (p *Parser) parseTypeExpr() ast.TypeExpr {
+    switch p.curToken.Type {
+    case token.IDENT:
+        named := &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+        if !p.peekTokenIs(token.LT) {
+            return named
+        }
+        p.nextToken()
+
+        generic := &ast.GenericType{Token: p.curToken, Base: named}
+        if p.peekTokenIs(token.GT) {
+            p.error(p.peekToken.Pos, "empty type argument list")
+            return nil
+        }
+        if generic.Args = p.parseTypeList(token.GT); generic.Args == nil {
+            return nil
+        }
+        return generic
+
+    case token.FUNCTION:
+        fn := &ast.FuncType{Token: p.curToken}
+        if !p.expectPeek(token.LPAREN) {
+            return nil
+        }
+        if fn.Params = p.parseTypeList(token.RPAREN); fn.Params == nil {
+            return nil
+        }
+        if !p.expectPeek(token.THINARROW) {
+            return nil
+        }
+        p.nextToken()
+        if fn.Result = p.parseTypeExpr(); fn.Result == nil {
+            return nil
+        }
+        return fn
+    }
+
+    msg := fmt.Sprintf("expected a type, got %s instead", p.curToken.Type)
+    p.error(p.curToken.Pos, msg)
+    return nil
 }
 
+// parseTypeList parses comma separated types up to the closing token
+// end, starting with the opening token as curToken.
+func (p *Parser) parseTypeList(end token.TokenType) []ast.TypeExpr {
+    list := []ast.TypeExpr{}
+
+    if p.peekTokenIs(end) {
+        p.nextToken()
+        return list
+    }
+
+    for {
+        p.nextToken()
+        t := p.parseTypeExpr()
+        if t == nil {
+            return nil
+        }
+        list = append(list, t)
+
+        if !p.peekTokenIs(token.COMMA) {
+            break
+        }
+        p.nextToken()
+    }
+
+    if !p.expectPeek(end) {
+        return nil
+    }
+
+    return list
+}
@@ -1,11 +1,17 @@
 package parser
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/UsamaHameed/monkey-interpreter/ast"
 	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -17,6 +23,7 @@ func TestLetStatements(t *testing.T) {
         {"let x = 5;", "x", 5},
         {"let y = true;", "y", true},
         {"let foobar = y;", "foobar", "y"},
+        {"let z = 1", "z", 1},
     }
 
     for _, test := range tests {
@@ -362,6 +369,7 @@ func TestParsingInfixExpressions(t *testing.T) {
         {"5 != 5;", 5, "!=", 5},
         {"true == true", true, "==", true},
         {"true != false", true, "!=", false},
+        {"a ?? 5", "a", "??", 5},
         {"false == false", false, "==", false},
     }
 
@@ -489,6 +497,62 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
             "!(true == true)",
             "(!(true == true))",
         },
+        {
+            "a + add(b * c) + d",
+            "((a + add((b * c))) + d)",
+        },
+        {
+            "add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
+            "add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
+        },
+        {
+            "if (a < b) { a } else { fn(x, y) { x } }",
+            "if ((a < b)) { a } else { fn(x, y) { x } }",
+        },
+        {
+            "x |> f(y) |> g",
+            "((x |> f(y)) |> g)",
+        },
+        {
+            "a + b |> f(c * d)",
+            "((a + b) |> f((c * d)))",
+        },
+        {
+            "a == b |> f",
+            "((a == b) |> f)",
+        },
+        {
+            "-a.b",
+            "(-a.b)",
+        },
+        {
+            "a.b * c.d",
+            "(a.b * c.d)",
+        },
+        {
+            "x |> list.push",
+            "(x |> list.push)",
+        },
+        {
+            "a ?? b == c",
+            "((a ?? b) == c)",
+        },
+        {
+            "-a ?? b",
+            "((-a) ?? b)",
+        },
+        {
+            "a ?? b ?? c",
+            "((a ?? b) ?? c)",
+        },
+        {
+            "a ?? b < c",
+            "(a ?? (b < c))",
+        },
+        {
+            "a?.b ?? null",
+            "(a?.b ?? null)",
+        },
     }
 
     for _, test := range tests {
@@ -762,3 +826,725 @@ func TestCallExpressionParsing(t *testing.T) {
     testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
     testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
 }
+
+func TestArrowFunctionParsing(t *testing.T) {
+    tests := []struct {
+        input          string
+        expectedParams []string
+        implicitReturn bool
+        expected       string
+    }{
+        {"x => x * 2;", []string{"x"}, true, "(x) => (x * 2)"},
+        {"(x) => x;", []string{"x"}, true, "(x) => x"},
+        {"() => 1;", []string{}, true, "() => 1"},
+        {"(a, b) => a + b;", []string{"a", "b"}, true, "(a, b) => (a + b)"},
+        {"(a, b) => { a + b; }", []string{"a", "b"}, false, "(a, b) => { (a + b) }"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+
+        if len(program.Statements) != 1 {
+            t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+                1, len(program.Statements))
+        }
+
+        stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+        if !ok {
+            t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+                program.Statements[0])
+        }
+
+        function, ok := stmt.Expression.(*ast.FunctionLiteral)
+        if !ok {
+            t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T",
+                stmt.Expression)
+        }
+
+        if function.ImplicitReturn != tt.implicitReturn {
+            t.Errorf("function.ImplicitReturn not %t. got=%t",
+                tt.implicitReturn, function.ImplicitReturn)
+        }
+
+        if len(function.Parameters) != len(tt.expectedParams) {
+            t.Fatalf("length parameters wrong. want %d, got=%d\n",
+                len(tt.expectedParams), len(function.Parameters))
+        }
+
+        for i, ident := range tt.expectedParams {
+            testLiteralExpression(t, function.Parameters[i], ident)
+        }
+
+        if len(function.Body.Statements) != 1 {
+            t.Fatalf("function.Body.Statements does not contain 1 statements. got=%d",
+                len(function.Body.Statements))
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+        }
+    }
+}
+
+func TestArrowFunctionInCallArguments(t *testing.T) {
+    input := "map(list, x => x * 2, (a, b) => a);"
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    stmt := program.Statements[0].(*ast.ExpressionStatement)
+    exp, ok := stmt.Expression.(*ast.CallExpression)
+    if !ok {
+        t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T",
+            stmt.Expression)
+    }
+
+    if len(exp.Arguments) != 3 {
+        t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+    }
+
+    testIdentifier(t, exp.Arguments[0], "list")
+
+    double, ok := exp.Arguments[1].(*ast.FunctionLiteral)
+    if !ok {
+        t.Fatalf("exp.Arguments[1] is not ast.FunctionLiteral. got=%T", exp.Arguments[1])
+    }
+    body := double.Body.Statements[0].(*ast.ExpressionStatement)
+    testInfixExpression(t, body.Expression, "x", "*", 2)
+
+    first, ok := exp.Arguments[2].(*ast.FunctionLiteral)
+    if !ok {
+        t.Fatalf("exp.Arguments[2] is not ast.FunctionLiteral. got=%T", exp.Arguments[2])
+    }
+    if len(first.Parameters) != 2 {
+        t.Fatalf("first.Parameters does not contain 2 parameters. got=%d", len(first.Parameters))
+    }
+}
+
+func TestArrowFunctionErrors(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"(a + b) => a;", "invalid arrow function parameter *ast.InfixExpression"},
+        {"(a, b);", "expected next token to be =>, got ; instead"},
+        {"() + 1;", "expected next token to be =>, got + instead"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) == 0 {
+            t.Fatalf("expected parser errors for %q, got none", tt.input)
+        }
+        if errors[0] != tt.expected {
+            t.Errorf("expected error %q, got=%q", tt.expected, errors[0])
+        }
+    }
+}
+
+func TestTypeAnnotationParsing(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"let x: int = 5;", "let x: int = 5;"},
+        {"let x = 5;", "let x = 5;"},
+        {"let f: fn(int, bool) -> int = g;", "let f: fn(int, bool) -> int = g;"},
+        {"let xs: list<int> = ys;", "let xs: list<int> = ys;"},
+        {"let m: map<int, list<bool>> = n;", "let m: map<int, list<bool>> = n;"},
+        {"let k: fn() -> fn(int) -> int = h;", "let k: fn() -> fn(int) -> int = h;"},
+        {"fn(a: int, b: bool) -> int { a }", "fn(a: int, b: bool) -> int { a }"},
+        {"fn(a, b: int) { a }", "fn(a, b: int) { a }"},
+        {"fn() -> bool { true }", "fn() -> bool { true }"},
+        {"(x: int) => x", "(x: int) => x"},
+        {"(a: list<int>, b) => { a }", "(a: list<int>, b) => { a }"},
+        {"map(xs, (x: int) => x)", "map(xs, (x: int) => x)"},
+        {"let id: fn(a) -> a = fn(x: a) -> a { x };", "let id: fn(a) -> a = fn(x: a) -> a { x };"},
+        {"a - b", "(a - b)"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+
+        if program.String() != tt.expected {
+            t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+        }
+    }
+}
+
+func TestTypeAnnotationAttachment(t *testing.T) {
+    input := "let f: fn(int) -> int = fn(a: int, b) -> list<int> { a };"
+
+    p := New(lexer.New(input))
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    let := program.Statements[0].(*ast.LetStatement)
+    ft, ok := let.Name.Annotation.(*ast.FuncType)
+    if !ok {
+        t.Fatalf("let annotation is not *ast.FuncType. got=%T", let.Name.Annotation)
+    }
+    if len(ft.Params) != 1 || ft.Result.String() != "int" {
+        t.Errorf("wrong function type %s", ft)
+    }
+
+    fn := let.Value.(*ast.FunctionLiteral)
+    if named, ok := fn.Parameters[0].Annotation.(*ast.NamedType); !ok || named.Name != "int" {
+        t.Errorf("wrong annotation for a. got=%v", fn.Parameters[0].Annotation)
+    }
+    if fn.Parameters[1].Annotation != nil {
+        t.Errorf("b should not be annotated. got=%v", fn.Parameters[1].Annotation)
+    }
+    generic, ok := fn.Result.(*ast.GenericType)
+    if !ok {
+        t.Fatalf("result is not *ast.GenericType. got=%T", fn.Result)
+    }
+    if generic.Base.Name != "list" || len(generic.Args) != 1 {
+        t.Errorf("wrong generic type %s", generic)
+    }
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+    tests := []struct {
+        input    string
+        expected string
+    }{
+        {"let x: = 5;", "expected a type, got = instead"},
+        {"let x: 5 = 5;", "expected a type, got INT instead"},
+        {"let xs: list<> = ys;", "empty type argument list"},
+        {"let xs: list<int = ys;", "expected next token to be >, got = instead"},
+        {"let f: fn(int) = g;", "expected next token to be ->, got = instead"},
+        {"fn(a: ) { a }", "expected a type, got ) instead"},
+        {"fn(a) -> { a }", "expected a type, got { instead"},
+        {"(x: int);", "expected next token to be =>, got ; instead"},
+        {"(x: ) => x", "expected a type, got ) instead"},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        p.ParseProgram()
+
+        errors := p.Errors()
+        if len(errors) == 0 {
+            t.Fatalf("expected parser errors for %q, got none", tt.input)
+        }
+        if errors[0] != tt.expected {
+            t.Errorf("%q: expected error %q, got=%q", tt.input, tt.expected, errors[0])
+        }
+    }
+}
+
+func TestPipeExpressionParsing(t *testing.T) {
+    input := "x |> add(1, 2);"
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    if len(program.Statements) != 1 {
+        t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+            1, len(program.Statements))
+    }
+
+    stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+    if !ok {
+        t.Fatalf("stmt is not ast.ExpressionStatement. got=%T",
+            program.Statements[0])
+    }
+
+    exp, ok := stmt.Expression.(*ast.PipeExpression)
+    if !ok {
+        t.Fatalf("stmt.Expression is not ast.PipeExpression. got=%T",
+            stmt.Expression)
+    }
+
+    if !testIdentifier(t, exp.Left, "x") {
+        return
+    }
+
+    call := exp.Call()
+    if !testIdentifier(t, call.Function, "add") {
+        return
+    }
+
+    if len(call.Arguments) != 3 {
+        t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+    }
+
+    testLiteralExpression(t, call.Arguments[0], "x")
+    testLiteralExpression(t, call.Arguments[1], 1)
+    testLiteralExpression(t, call.Arguments[2], 2)
+
+    right, ok := exp.Right.(*ast.CallExpression)
+    if !ok {
+        t.Fatalf("exp.Right is not ast.CallExpression. got=%T", exp.Right)
+    }
+    if len(right.Arguments) != 2 {
+        t.Errorf("lowering modified exp.Right, got %d arguments", len(right.Arguments))
+    }
+}
+
+func TestMemberExpressionParsing(t *testing.T) {
+    tests := []struct {
+        input            string
+        expectedObject   string
+        expectedProperty string
+        optional         bool
+    }{
+        {"user.name;", "user", "name", false},
+        {"user?.name;", "user", "name", true},
+    }
+
+    for _, tt := range tests {
+        l := lexer.New(tt.input)
+        p := New(l)
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+
+        if len(program.Statements) != 1 {
+            t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+                1, len(program.Statements))
+        }
+
+        stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+        if !ok {
+            t.Fatalf("stmt is not ast.ExpressionStatement. got=%T",
+                program.Statements[0])
+        }
+
+        exp, ok := stmt.Expression.(*ast.MemberExpression)
+        if !ok {
+            t.Fatalf("stmt.Expression is not ast.MemberExpression. got=%T",
+                stmt.Expression)
+        }
+
+        if !testIdentifier(t, exp.Object, tt.expectedObject) {
+            return
+        }
+
+        if !testIdentifier(t, exp.Property, tt.expectedProperty) {
+            return
+        }
+
+        if exp.Optional != tt.optional {
+            t.Errorf("exp.Optional not %t. got=%t", tt.optional, exp.Optional)
+        }
+    }
+}
+
+func TestMethodCallExpressionParsing(t *testing.T) {
+    input := "a.b(c).d;"
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    if len(program.Statements) != 1 {
+        t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+            1, len(program.Statements))
+    }
+
+    stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+    if !ok {
+        t.Fatalf("stmt is not ast.ExpressionStatement. got=%T",
+            program.Statements[0])
+    }
+
+    outer, ok := stmt.Expression.(*ast.MemberExpression)
+    if !ok {
+        t.Fatalf("stmt.Expression is not ast.MemberExpression. got=%T",
+            stmt.Expression)
+    }
+
+    if !testIdentifier(t, outer.Property, "d") {
+        return
+    }
+
+    call, ok := outer.Object.(*ast.CallExpression)
+    if !ok {
+        t.Fatalf("outer.Object is not ast.CallExpression. got=%T", outer.Object)
+    }
+
+    if len(call.Arguments) != 1 {
+        t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+    }
+
+    testLiteralExpression(t, call.Arguments[0], "c")
+
+    inner, ok := call.Function.(*ast.MemberExpression)
+    if !ok {
+        t.Fatalf("call.Function is not ast.MemberExpression. got=%T", call.Function)
+    }
+
+    if !testIdentifier(t, inner.Object, "a") {
+        return
+    }
+
+    testIdentifier(t, inner.Property, "b")
+}
+
+func TestMemberExpressionErrors(t *testing.T) {
+    l := lexer.New("a.5;")
+    p := New(l)
+    p.ParseProgram()
+
+    errors := p.Errors()
+    if len(errors) == 0 {
+        t.Fatalf("expected parser errors, got none")
+    }
+
+    expected := "expected next token to be IDENT, got INT instead"
+    if errors[0] != expected {
+        t.Errorf("expected error %q, got=%q", expected, errors[0])
+    }
+}
+
+func TestNullLiteralExpression(t *testing.T) {
+    input := "null;"
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    if len(program.Statements) != 1 {
+        t.Fatalf("program did not return enough statements. got=%d",
+            len(program.Statements))
+    }
+    stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+    if !ok {
+        t.Fatalf("the root node aka program.Statements[0] is not an ast.ExpressionStatement, got=%T",
+            program.Statements[0])
+    }
+
+    literal, ok := stmt.Expression.(*ast.NullLiteral)
+    if !ok {
+        t.Fatalf("exp not *ast.NullLiteral. got=%T", stmt.Expression)
+    }
+    if literal.TokenLiteral() != "null" {
+        t.Errorf("literal.TokenLiteral not %s. got=%s", "null",
+            literal.TokenLiteral())
+    }
+}
+
+func TestStatementsWithoutSemicolons(t *testing.T) {
+    input := `let x = 1
+let y = 2
+if (x) { return x } else { return y }
+y`
+
+    l := lexer.New(input)
+    p := New(l)
+    program := p.ParseProgram()
+    checkParseErrors(t, p)
+
+    if len(program.Statements) != 4 {
+        t.Fatalf("program.Statements does not contain 4 statements. got=%d",
+            len(program.Statements))
+    }
+
+    if !testLetStatement(t, program.Statements[0], "x") {
+        return
+    }
+    if !testLetStatement(t, program.Statements[1], "y") {
+        return
+    }
+
+    stmt := program.Statements[2].(*ast.ExpressionStatement)
+    exp, ok := stmt.Expression.(*ast.IfExpression)
+    if !ok {
+        t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+    }
+    if len(exp.Consequence.Statements) != 1 || len(exp.Alternative.Statements) != 1 {
+        t.Errorf("if branches do not contain 1 statement each. got=%d, %d",
+            len(exp.Consequence.Statements), len(exp.Alternative.Statements))
+    }
+}
+
+func TestComments(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {"// only a comment", ""},
+        {"let x = 1; // one\nx", "let x = 1;x"},
+        {"// leading\nlet x = 1;\n// between\nx", "let x = 1;x"},
+        {"let add = fn(a, // first\n b) { // body\n a + b // sum\n};", "let add = fn(a, b) { (a + b) };"},
+        {"x / 2 // halved", "(x / 2)"},
+        {"f(1) //", "f(1)"},
+    }
+
+    for _, tt := range tests {
+        p := New(lexer.New(tt.input))
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+
+        if got := program.String(); got != tt.expected {
+            t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestCommentErrorPositions(t *testing.T) {
+    p := New(lexer.New("// header\nlet x = 1; // note\n  let = 2;"))
+    p.ParseProgram()
+
+    if len(p.ErrorList()) == 0 {
+        t.Fatalf("expected errors")
+    }
+    if pos := p.ErrorList()[0].Pos; pos.Line != 3 || pos.Column != 7 {
+        t.Errorf("comments shifted the error position to %s", pos)
+    }
+
+    // slashes on different lines are not a comment
+    p = New(lexer.New("x /\n/ y"))
+    p.ParseProgram()
+    if len(p.Errors()) == 0 {
+        t.Errorf("expected errors for a slash without operand")
+    }
+}
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestGolden(t *testing.T) {
+    inputs, err := filepath.Glob(filepath.Join("testdata", "*.mk"))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, input := range inputs {
+        src, err := os.ReadFile(input)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        p := New(lexer.New(string(src)))
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+
+        var got bytes.Buffer
+        if err := ast.Fprint(&got, program); err != nil {
+            t.Fatalf("%s: Fprint returned error: %s", input, err)
+        }
+
+        golden := strings.TrimSuffix(input, ".mk") + ".golden"
+        if *update {
+            if err := os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+                t.Fatal(err)
+            }
+            continue
+        }
+
+        expected, err := os.ReadFile(golden)
+        if err != nil {
+            t.Fatalf("%s (run go test -update to create it)", err)
+        }
+
+        if !bytes.Equal(got.Bytes(), expected) {
+            t.Errorf("%s: tree does not match %s (run go test -update to accept).\ngot=\n%s",
+                input, golden, got.String())
+        }
+    }
+}
+
+func TestParseTemplate(t *testing.T) {
+    ident := func(name string) *ast.Identifier {
+        return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+    }
+    parse := func(input string) *ast.Program {
+        p := New(lexer.New(input))
+        program := p.ParseProgram()
+        checkParseErrors(t, p)
+        return program
+    }
+
+    tests := []struct {
+        template    string
+        bindings    map[string]ast.Node
+        expected    string
+    }{
+        {
+            "let $name = $value + 1;",
+            map[string]ast.Node{"name": ident("x"), "value": parse("a * b").Statements[0].(*ast.ExpressionStatement).Expression},
+            "let x = ((a * b) + 1);",
+        },
+        {
+            "fn($a, b) { $a.$prop }",
+            map[string]ast.Node{"a": ident("self"), "prop": ident("name")},
+            "fn(self, b) { self.name }",
+        },
+        {
+            "$f($x) |> $g",
+            map[string]ast.Node{"f": ident("f"), "x": ident("x"), "g": ident("g")},
+            "(f(x) |> g)",
+        },
+        {
+            "$x => $x + 1",
+            map[string]ast.Node{"x": ident("n")},
+            "(n) => (n + 1)",
+        },
+        {
+            "let a = 1; $stmt; return a;",
+            map[string]ast.Node{"stmt": parse("let b = 2;").Statements[0]},
+            "let a = 1;let b = 2;return a;",
+        },
+        {
+            "if ($cond) { $then }",
+            map[string]ast.Node{"cond": ident("ok"), "then": ident("y")},
+            "if (ok) { y }",
+        },
+        {
+            "let $name: int = fn($a: int) -> int { $a };",
+            map[string]ast.Node{"name": ident("f"), "a": ident("n")},
+            "let f: int = fn(n: int) -> int { n };",
+        },
+    }
+
+    for _, tt := range tests {
+        program, err := ParseTemplate(tt.template, tt.bindings)
+        if err != nil {
+            t.Errorf("ParseTemplate(%q) returned error: %s", tt.template, err)
+            continue
+        }
+
+        if program.String() != tt.expected {
+            t.Errorf("ParseTemplate(%q) wrong. expected=%q, got=%q", tt.template, tt.expected, program.String())
+        }
+
+        ast.Inspect(program, func(n ast.Node) bool {
+            if _, ok := n.(*ast.Placeholder); ok {
+                t.Errorf("ParseTemplate(%q) left a placeholder in the tree", tt.template)
+            }
+            if id, ok := n.(*ast.Identifier); ok && id.Token.Type == token.PLACEHOLDER {
+                t.Errorf("ParseTemplate(%q) left placeholder identifier %s", tt.template, id.Value)
+            }
+            return true
+        })
+    }
+}
+
+func TestParseTemplateCopies(t *testing.T) {
+    x := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "n"}, Value: "n"}
+    program, err := ParseTemplate("$x => $x + 1", map[string]ast.Node{"x": x})
+    if err != nil {
+        t.Fatalf("ParseTemplate returned error: %s", err)
+    }
+
+    fl := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+    param := fl.Parameters[0]
+    operand := fl.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression).Left
+
+    if param == operand {
+        t.Errorf("both uses of $x are the same node")
+    }
+    if param == x || operand == x {
+        t.Errorf("the bound node was inserted without a copy")
+    }
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+    let := New(lexer.New("let b = 2;")).ParseProgram().Statements[0]
+    x := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}
+    one := &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1}
+
+    tests := []struct {
+        template    string
+        bindings    map[string]ast.Node
+        expected    []string
+    }{
+        {
+            "let $name = $value;",
+            map[string]ast.Node{"name": x},
+            []string{"1:13: unbound placeholder $value"},
+        },
+        {
+            "$a + $b",
+            nil,
+            []string{"1:1: unbound placeholder $a", "1:6: unbound placeholder $b"},
+        },
+        {
+            "let $name = 1;",
+            map[string]ast.Node{"name": one},
+            []string{"1:5: cannot use *ast.IntegerLiteral as an identifier for $name"},
+        },
+        {
+            "1 + $s",
+            map[string]ast.Node{"s": let},
+            []string{"1:5: cannot use *ast.LetStatement as an expression for $s"},
+        },
+        {
+            "let = $x;",
+            map[string]ast.Node{"x": x},
+            []string{"expected next token to be IDENT, got = instead", "no prefix parse function for = found"},
+        },
+    }
+
+    for _, tt := range tests {
+        program, err := ParseTemplate(tt.template, tt.bindings)
+        if err == nil {
+            t.Errorf("ParseTemplate(%q) expected an error, got %q", tt.template, program.String())
+            continue
+        }
+
+        terr, ok := err.(*TemplateError)
+        if !ok {
+            t.Fatalf("error is not *TemplateError. got=%T", err)
+        }
+
+        if fmt.Sprint(terr.Errors) != fmt.Sprint(tt.expected) {
+            t.Errorf("ParseTemplate(%q) errors wrong.\nexpected=%q\ngot=     %q", tt.template, tt.expected, terr.Errors)
+        }
+    }
+}
+
+func TestPlaceholderOutsideTemplate(t *testing.T) {
+    p := New(lexer.New("let $x = 1;"))
+    p.ParseProgram()
+
+    if len(p.Errors()) == 0 {
+        t.Fatalf("expected parse errors for a placeholder outside a template")
+    }
+}
+
+func TestErrorPositions(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {"let = 5;", []string{"1:5: expected next token to be IDENT, got = instead", "1:5: no prefix parse function for = found"}},
+        {"x +\n  ;", []string{"2:3: no prefix parse function for ; found"}},
+        {"99999999999999999999", []string{"1:1: could not parse \"99999999999999999999\" as an int"}},
+        {"(a, 1) => a", []string{"1:5: invalid arrow function parameter *ast.IntegerLiteral", "1:8: no prefix parse function for => found"}},
+    }
+
+    for _, tt := range tests {
+        p := New(lexer.New(tt.input))
+        p.ParseProgram()
+
+        got := []string{}
+        for _, err := range p.ErrorList() {
+            got = append(got, err.Error())
+        }
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("errors for %q wrong.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+        if len(p.ErrorList()) != len(p.Errors()) {
+            t.Errorf("ErrorList and Errors differ in length for %q", tt.input)
+        }
+    }
+}
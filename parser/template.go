@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/ast/astutil"
+	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// TemplateError lists the problems found by ParseTemplate.
+type TemplateError struct {
+    Errors []string
+}
+
+func (e *TemplateError) Error() string {
+    return "template: " + strings.Join(e.Errors, "; ")
+}
+
+// ParseTemplate parses src with $name placeholders and substitutes each
+// one with bindings[name]:
+//
+//    ParseTemplate("let $name = $value + 1;", map[string]ast.Node{
+//        "name":  &ast.Identifier{...},
+//        "value": &ast.IntegerLiteral{...},
+//    })
+//
+// A placeholder in an identifier position (a let name, a parameter or
+// a member property) must be bound to an *ast.Identifier, one in an
+// expression position to an ast.Expression. A placeholder that forms a
+// whole statement, as in `$body;`, may also be bound to an
+// ast.Statement. Each placeholder gets a copy of the node bound to it,
+// so a name used twice in the template yields two distinct nodes.
+//
+// Parse errors, unbound placeholders and bindings of the wrong kind are
+// returned as a *TemplateError.
+func ParseTemplate(src string, bindings map[string]ast.Node) (*ast.Program, error) {
+    p := New(lexer.NewTemplate(src))
+    program := p.ParseProgram()
+
+    if len(p.Errors()) != 0 {
+        return nil, &TemplateError{Errors: p.Errors()}
+    }
+
+    errors := []string{}
+    lookup := func(tok token.Token) ast.Node {
+        node, ok := bindings[tok.Literal[1:]]
+        if !ok || node == nil {
+            errors = append(errors, fmt.Sprintf("%s: unbound placeholder %s", tok.Pos, tok.Literal))
+            return nil
+        }
+        return node
+    }
+    mismatch := func(tok token.Token, node ast.Node, kind string) {
+        msg := fmt.Sprintf("%s: cannot use %T as %s for %s", tok.Pos, node, kind, tok.Literal)
+        errors = append(errors, msg)
+    }
+
+    astutil.Apply(program, func(c *astutil.Cursor) bool {
+        switch n := c.Node().(type) {
+        case *ast.ExpressionStatement:
+            ph, ok := n.Expression.(*ast.Placeholder)
+            if !ok {
+                return true
+            }
+            if s, ok := bindings[ph.Name].(ast.Statement); ok && s != nil {
+                if _, isExpr := s.(ast.Expression); !isExpr {
+                    c.Replace(ast.Copy(s))
+                    return false
+                }
+            }
+
+        case *ast.Placeholder:
+            node := lookup(n.Token)
+            if node == nil {
+                return false
+            }
+            e, ok := node.(ast.Expression)
+            if !ok {
+                mismatch(n.Token, node, "an expression")
+                return false
+            }
+            c.Replace(ast.Copy(e))
+            return false
+
+        case *ast.Identifier:
+            if n.Token.Type != token.PLACEHOLDER {
+                return true
+            }
+            node := lookup(n.Token)
+            if node == nil {
+                return false
+            }
+            if _, ok := node.(*ast.Identifier); !ok {
+                mismatch(n.Token, node, "an identifier")
+                return false
+            }
+            ident := ast.Copy(node).(*ast.Identifier)
+            // keep the annotation written in the template, as in
+            // `let $name: int = ...`
+            if n.Annotation != nil && ident.Annotation == nil {
+                ident.Annotation = n.Annotation
+            }
+            c.Replace(ident)
+            return false
+        }
+
+        return true
+    }, nil)
+
+    if len(errors) != 0 {
+        return nil, &TemplateError{Errors: errors}
+    }
+
+    return program, nil
+}
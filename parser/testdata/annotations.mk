@@ -0,0 +1,5 @@
+let limit: int = 10;
+let apply: fn(fn(int) -> int, int) -> int = fn(f: fn(int) -> int, x: int) -> int {
+    f(x)
+};
+let xs: list<int> = empty;
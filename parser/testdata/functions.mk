@@ -0,0 +1,3 @@
+let add = fn(a, b) { a + b };
+let twice = f => x => f(f(x));
+if (add(1, 2) > 2) { twice } else { null }
@@ -0,0 +1,2 @@
+let name = user?.profile.name ?? guest;
+list |> map(x => x * 2) |> sum;
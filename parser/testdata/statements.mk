@@ -0,0 +1,3 @@
+let x = 5;
+let y = x * (2 + 3);
+return -y;
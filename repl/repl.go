@@ -1,32 +1,85 @@
+// Package repl implements the Monkey read-eval-print loop. Each input
+// line is parsed, and the REPL prints the program in canonical form, or
+// as a DOT graph, or the errors that kept it from parsing.
 package repl
 
 import (
 	"bufio"
 	"fmt"
 	"io"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast/dot"
+	"github.com/UsamaHameed/monkey-interpreter/format"
 	"github.com/UsamaHameed/monkey-interpreter/lexer"
+	"github.com/UsamaHameed/monkey-interpreter/parser"
 	"github.com/UsamaHameed/monkey-interpreter/token"
 )
 
+const prompt = ">> "
+
+// Options change what the REPL prints for each input.
+type Options struct {
+    // Tokens prints the tokens of the input, one per line, before the
+    // program.
+    Tokens  bool
+    // Dot prints the program as a Graphviz DOT graph instead of in
+    // canonical form.
+    Dot     bool
+}
+
+// Start runs the REPL with the default options, reading lines from in
+// until it is exhausted and writing to out.
 func Start(in io.Reader, out io.Writer) {
+    Run(in, out, Options{})
+}
 
+// Run runs the REPL with the given options.
+func Run(in io.Reader, out io.Writer, options Options) {
     scanner := bufio.NewScanner(in)
 
     for {
+        fmt.Fprint(out, prompt)
+        if !scanner.Scan() {
+            return
+        }
+        line := scanner.Text()
 
-        fmt.Fprintf(out, ">> ")
-        scanned := scanner.Scan()
+        if options.Tokens {
+            printTokens(out, line)
+        }
 
-        if !scanned {
-            return
+        p := parser.New(lexer.New(line))
+        program := p.ParseProgram()
+
+        if len(p.ErrorList()) != 0 {
+            printParserErrors(out, p.ErrorList())
+            continue
         }
 
-        line := scanner.Text()
-        l := lexer.New(line)
+        if options.Dot {
+            if err := dot.Write(out, program); err != nil {
+                fmt.Fprintf(out, "%s\n", err)
+            }
+            continue
+        }
 
-        for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
-            fmt.Println(t.Literal, t.Type)
-            fmt.Fprintf(out, "%+v\n", t)
+        if err := format.Node(out, program); err != nil {
+            fmt.Fprintf(out, "%s\n", err)
         }
     }
 }
+
+func printTokens(out io.Writer, line string) {
+    l := lexer.New(line)
+
+    for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+        fmt.Fprintf(out, "%s %s %q\n", t.Pos, t.Type, t.Literal)
+    }
+}
+
+func printParserErrors(out io.Writer, errors []parser.Error) {
+    fmt.Fprintf(out, "parser errors:\n")
+    for _, err := range errors {
+        fmt.Fprintf(out, "    %s\n", err)
+    }
+}
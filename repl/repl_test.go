@@ -0,0 +1,97 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func run(input string, options Options) string {
+    var out bytes.Buffer
+    Run(strings.NewReader(input), &out, options)
+    return out.String()
+}
+
+func TestStart(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {"", ">> "},
+        {"let x = 1 + 2 * 3;\n", ">> let x = 1 + 2 * 3;\n>> "},
+        {"let add = fn(a,b){a+b};\nadd(1,2)\n", ">> let add = fn(a, b) {\n    a + b;\n};\n>> add(1, 2);\n>> "},
+        {"x |> f(1)", ">> x |> f(1);\n>> "},
+        {"\n", ">> >> "},
+        {
+            "let = 5;\nlet x 1;\n",
+            ">> parser errors:\n" +
+            "    1:5: expected next token to be IDENT, got = instead\n" +
+            "    1:5: no prefix parse function for = found\n" +
+            ">> parser errors:\n" +
+            "    1:7: expected next token to be =, got INT instead\n" +
+            ">> ",
+        },
+    }
+
+    for _, tt := range tests {
+        var out bytes.Buffer
+        Start(strings.NewReader(tt.input), &out)
+
+        if got := out.String(); got != tt.expected {
+            t.Errorf("Start(%q) wrong output.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestTokens(t *testing.T) {
+    expected := ">> " +
+        "1:1 LET \"let\"\n" +
+        "1:5 IDENT \"x\"\n" +
+        "1:7 = \"=\"\n" +
+        "1:9 INT \"1\"\n" +
+        "1:10 ; \";\"\n" +
+        "let x = 1;\n" +
+        ">> "
+
+    if got := run("let x = 1;\n", Options{Tokens: true}); got != expected {
+        t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+    }
+
+    if got := run("let x = 1;\n", Options{}); strings.Contains(got, "IDENT") {
+        t.Errorf("tokens printed without Options.Tokens: %q", got)
+    }
+}
+
+func TestParserErrorsStillPrintTokens(t *testing.T) {
+    got := run("1 +\n", Options{Tokens: true})
+    expected := ">> 1:1 INT \"1\"\n1:3 + \"+\"\nparser errors:\n"
+
+    if !strings.HasPrefix(got, expected) {
+        t.Errorf("wrong output.\nexpected prefix=%q\ngot=            %q", expected, got)
+    }
+}
+
+func TestDot(t *testing.T) {
+    got := run("-x\nlet = 1;\n", Options{Dot: true})
+
+    expected := ">> " +
+        "digraph AST {\n" +
+        "    ordering=out;\n" +
+        "    node [shape=box, fontname=\"monospace\"];\n" +
+        "    n0 [label=\"Program\"];\n" +
+        "    n1 [label=\"ExpressionStatement\"];\n" +
+        "    n2 [label=\"PrefixExpression\\n-\"];\n" +
+        "    n3 [label=\"Identifier\\nx\"];\n" +
+        "    n2 -> n3 [label=\"Right\"];\n" +
+        "    n1 -> n2 [label=\"Expression\"];\n" +
+        "    n0 -> n1 [label=\"Statements[0]\"];\n" +
+        "}\n" +
+        ">> parser errors:\n" +
+        "    1:5: expected next token to be IDENT, got = instead\n" +
+        "    1:5: no prefix parse function for = found\n" +
+        ">> "
+
+    if got != expected {
+        t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+    }
+}
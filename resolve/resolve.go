@@ -0,0 +1,322 @@
+// Package resolve links identifiers to the let bindings and function
+// parameters they refer to.
+//
+// Programs, function literals and blocks each get a scope. A let binding
+// is visible from the end of its statement to the end of its scope, and
+// a parameter throughout its function. Code inside a function literal
+// runs only when the function is called, so it may also refer to
+// bindings declared later in an enclosing scope, as in mutually
+// recursive functions.
+package resolve
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+type ObjKind int
+
+const (
+    Builtin ObjKind = iota
+    Let
+    Param
+)
+
+var objKindNames = [...]string{
+    Builtin: "builtin",
+    Let:     "let",
+    Param:   "param",
+}
+
+func (k ObjKind) String() string {
+    return objKindNames[k]
+}
+
+// An Object is a named binding. Decl is the identifier that declares it,
+// or nil for a builtin.
+type Object struct {
+    Name    string
+    Kind    ObjKind
+    Decl    *ast.Identifier
+    Scope   *Scope
+}
+
+// A Scope maps names to the objects declared in it. Node is the
+// *ast.Program, *ast.FunctionLiteral or *ast.BlockStatement that opens
+// it, or nil for the scope of builtins.
+type Scope struct {
+    Parent      *Scope
+    Children    []*Scope
+    Node        ast.Node
+
+    objects     map[string]*Object
+    // let bindings not yet reached, in order
+    pending     map[string][]*Object
+    isFunc      bool
+}
+
+func newScope(parent *Scope, node ast.Node) *Scope {
+    s := &Scope{
+        Parent:  parent,
+        Node:    node,
+        objects: map[string]*Object{},
+        pending: map[string][]*Object{},
+    }
+    if parent != nil {
+        parent.Children = append(parent.Children, s)
+    }
+    return s
+}
+
+// Lookup returns the object named name in s, or nil. If name is bound
+// more than once in s, the last binding is returned.
+func (s *Scope) Lookup(name string) *Object {
+    return s.objects[name]
+}
+
+// LookupParent looks name up in s and then its parents.
+func (s *Scope) LookupParent(name string) *Object {
+    for ; s != nil; s = s.Parent {
+        if obj := s.objects[name]; obj != nil {
+            return obj
+        }
+    }
+    return nil
+}
+
+// Names returns the sorted names declared in s.
+func (s *Scope) Names() []string {
+    names := []string{}
+    for name := range s.objects {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Info holds the result of resolving a program.
+type Info struct {
+    // Defs maps declaring identifiers to their objects.
+    Defs    map[*ast.Identifier]*Object
+    // Uses maps identifiers that refer to an object to it.
+    Uses    map[*ast.Identifier]*Object
+    // Scopes maps the nodes that open a scope to it. The body of a
+    // function literal maps to the scope of the function.
+    Scopes  map[ast.Node]*Scope
+    // Universe holds the builtins; the program's scope is its child.
+    Universe *Scope
+}
+
+// An Error is a problem found while resolving, at the position of the
+// offending identifier.
+type Error struct {
+    Pos token.Position
+    Msg string
+}
+
+func (e *Error) Error() string {
+    return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Resolve resolves the identifiers of program. builtins are names that
+// are defined before the program starts, such as functions provided by
+// the host. Undefined names, duplicate parameters and uses of a binding
+// before its let statement are reported as errors; the returned Info
+// is complete either way.
+func Resolve(program *ast.Program, builtins ...string) (*Info, []*Error) {
+    r := &resolver{
+        info: &Info{
+            Defs:   map[*ast.Identifier]*Object{},
+            Uses:   map[*ast.Identifier]*Object{},
+            Scopes: map[ast.Node]*Scope{},
+        },
+        errors: []*Error{},
+    }
+
+    r.info.Universe = newScope(nil, nil)
+    for _, name := range builtins {
+        r.info.Universe.objects[name] = &Object{Name: name, Kind: Builtin, Scope: r.info.Universe}
+    }
+
+    r.scope = r.info.Universe
+    r.openScope(program)
+    r.statements(program.Statements)
+
+    return r.info, r.errors
+}
+
+type resolver struct {
+    info    *Info
+    scope   *Scope
+    errors  []*Error
+}
+
+func (r *resolver) errorf(pos token.Position, format string, args ...interface{}) {
+    r.errors = append(r.errors, &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (r *resolver) openScope(node ast.Node) *Scope {
+    r.scope = newScope(r.scope, node)
+    r.info.Scopes[node] = r.scope
+    return r.scope
+}
+
+func (r *resolver) closeScope() {
+    r.scope = r.scope.Parent
+}
+
+// statements declares the let bindings of list as pending, so forward
+// references to them can be recognized, and then resolves list in
+// order.
+func (r *resolver) statements(list []ast.Statement) {
+    for _, s := range list {
+        if let, ok := s.(*ast.LetStatement); ok && let.Name != nil {
+            obj := &Object{Name: let.Name.Value, Kind: Let, Decl: let.Name, Scope: r.scope}
+            r.scope.pending[obj.Name] = append(r.scope.pending[obj.Name], obj)
+            r.info.Defs[let.Name] = obj
+        }
+    }
+
+    for _, s := range list {
+        r.statement(s)
+    }
+}
+
+func (r *resolver) statement(s ast.Statement) {
+    switch s := s.(type) {
+    case *ast.LetStatement:
+        r.expression(s.Value)
+        if s.Name == nil {
+            return
+        }
+        name := s.Name.Value
+        obj := r.scope.pending[name][0]
+        r.scope.pending[name] = r.scope.pending[name][1:]
+        r.scope.objects[name] = obj
+
+    case *ast.ReturnStatement:
+        r.expression(s.ReturnValue)
+
+    case *ast.ExpressionStatement:
+        r.expression(s.Expression)
+
+    case nil:
+
+    default:
+        panic(fmt.Sprintf("resolve: unexpected statement type %T", s))
+    }
+}
+
+func (r *resolver) block(b *ast.BlockStatement) {
+    if b == nil {
+        return
+    }
+    r.openScope(b)
+    r.statements(b.Statements)
+    r.closeScope()
+}
+
+func (r *resolver) expression(e ast.Expression) {
+    switch e := e.(type) {
+    case *ast.Identifier:
+        r.use(e)
+
+    case *ast.IntegerLiteral, *ast.Boolean, *ast.NullLiteral, *ast.Placeholder, nil:
+
+    case *ast.PrefixExpression:
+        r.expression(e.Right)
+
+    case *ast.InfixExpression:
+        r.expression(e.Left)
+        r.expression(e.Right)
+
+    case *ast.PipeExpression:
+        r.expression(e.Left)
+        r.expression(e.Right)
+
+    case *ast.MemberExpression:
+        // the property is a field name, not a reference
+        r.expression(e.Object)
+
+    case *ast.CallExpression:
+        r.expression(e.Function)
+        for _, a := range e.Arguments {
+            r.expression(a)
+        }
+
+    case *ast.IfExpression:
+        r.expression(e.Condition)
+        r.block(e.Consequence)
+        r.block(e.Alternative)
+
+    case *ast.FunctionLiteral:
+        r.function(e)
+
+    case *ast.BlockStatement:
+        r.block(e)
+
+    default:
+        panic(fmt.Sprintf("resolve: unexpected expression type %T", e))
+    }
+}
+
+// function resolves fl in a scope holding its parameters and the
+// statements of its body.
+func (r *resolver) function(fl *ast.FunctionLiteral) {
+    scope := r.openScope(fl)
+    scope.isFunc = true
+
+    for _, p := range fl.Parameters {
+        obj := &Object{Name: p.Value, Kind: Param, Decl: p, Scope: scope}
+        if scope.objects[p.Value] != nil {
+            r.errorf(p.Token.Pos, "duplicate parameter %s", p.Value)
+        } else {
+            scope.objects[p.Value] = obj
+        }
+        r.info.Defs[p] = obj
+    }
+
+    if fl.Body != nil {
+        r.info.Scopes[fl.Body] = scope
+        r.statements(fl.Body.Statements)
+    }
+
+    r.closeScope()
+}
+
+// use resolves a reference to the binding it denotes: the innermost
+// binding already declared, or one declared later in a scope enclosing
+// the current function.
+func (r *resolver) use(id *ast.Identifier) {
+    var later *Object
+    crossed := false
+
+    for s := r.scope; s != nil; s = s.Parent {
+        if obj := s.objects[id.Value]; obj != nil {
+            r.info.Uses[id] = obj
+            return
+        }
+        if pending := s.pending[id.Value]; len(pending) > 0 {
+            if crossed {
+                r.info.Uses[id] = pending[0]
+                return
+            }
+            if later == nil {
+                later = pending[0]
+            }
+        }
+        if s.isFunc {
+            crossed = true
+        }
+    }
+
+    if later != nil {
+        r.errorf(id.Token.Pos, "%s used before its definition at %s", id.Value, later.Decl.Token.Pos)
+        return
+    }
+
+    r.errorf(id.Token.Pos, "undefined: %s", id.Value)
+}
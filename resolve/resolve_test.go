@@ -0,0 +1,176 @@
+package resolve
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+// uses returns, for each use of an identifier in source order, the
+// position of its declaration, or "builtin" or "?".
+func uses(program *ast.Program, info *Info) []string {
+    result := []string{}
+    var inspect func(n ast.Node) bool
+    inspect = func(n ast.Node) bool {
+        if m, ok := n.(*ast.MemberExpression); ok {
+            ast.Inspect(m.Object, inspect)
+            return false
+        }
+        id, ok := n.(*ast.Identifier)
+        if !ok || info.Defs[id] != nil {
+            return true
+        }
+        obj := info.Uses[id]
+        switch {
+        case obj == nil:
+            result = append(result, id.Value+"->?")
+        case obj.Decl == nil:
+            result = append(result, id.Value+"->builtin")
+        default:
+            result = append(result, fmt.Sprintf("%s->%s", id.Value, obj.Decl.Token.Pos))
+        }
+        return true
+    }
+    ast.Inspect(program, inspect)
+    return result
+}
+
+func TestResolveUses(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {
+            "let x = 1; x;",
+            []string{"x->1:5"},
+        },
+        {
+            "let x = 1; let f = fn(x) { x }; x;",
+            []string{"x->1:23", "x->1:5"},
+        },
+        {
+            "let x = 1; let x = x + 1; x;",
+            []string{"x->1:5", "x->1:16"},
+        },
+        {
+            "let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) } };",
+            []string{"n->1:14", "n->1:14", "fib->1:5", "n->1:14"},
+        },
+        {
+            "let even = fn(n) { odd(n) }; let odd = fn(n) { even(n) };",
+            []string{"odd->1:34", "n->1:15", "even->1:5", "n->1:43"},
+        },
+        {
+            "let a = 1; if (a) { let a = 2; a } else { a }",
+            []string{"a->1:5", "a->1:25", "a->1:5"},
+        },
+        {
+            "fn(a) { fn(b) { fn(c) { a + b + c } } }",
+            []string{"a->1:4", "b->1:12", "c->1:20"},
+        },
+        {
+            "let o = 1; o.field?.other;",
+            []string{"o->1:5"},
+        },
+        {
+            "puts(len(1))",
+            []string{"puts->builtin", "len->builtin"},
+        },
+        {
+            "let inc = x => x + 1; 1 |> inc;",
+            []string{"x->1:11", "inc->1:5"},
+        },
+    }
+
+    for _, tt := range tests {
+        program := testutil.Parse(t, tt.input)
+        info, errs := Resolve(program, "puts", "len")
+        if len(errs) != 0 {
+            t.Errorf("Resolve(%q) returned errors: %v", tt.input, errs)
+        }
+
+        got := uses(program, info)
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("Resolve(%q) wrong.\nexpected=%v\ngot=     %v", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestResolveErrors(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {"x", []string{"1:1: undefined: x"}},
+        {"let f = fn(a, b, a) { a };", []string{"1:18: duplicate parameter a"}},
+        {"y; let y = 1;", []string{"1:1: y used before its definition at 1:8"}},
+        {"let x = x;", []string{"1:9: x used before its definition at 1:5"}},
+        {"if (true) { z; let z = 1; }", []string{"1:13: z used before its definition at 1:20"}},
+        {"if (true) { let z = 1; } z", []string{"1:26: undefined: z"}},
+        {"fn(a) { b }; fn(b) { a }", []string{"1:9: undefined: b", "1:22: undefined: a"}},
+    }
+
+    for _, tt := range tests {
+        _, errs := Resolve(testutil.Parse(t, tt.input))
+
+        got := []string{}
+        for _, err := range errs {
+            got = append(got, err.Error())
+        }
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("Resolve(%q) errors wrong.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestScopes(t *testing.T) {
+    program := testutil.Parse(t, "let a = 1; let f = fn(x, y) { let z = x; if (z) { let w = y; w } }; f(a, a);")
+    info, errs := Resolve(program)
+    if len(errs) != 0 {
+        t.Fatalf("unexpected errors: %v", errs)
+    }
+
+    top := info.Scopes[program]
+    if top.Parent != info.Universe {
+        t.Errorf("program scope is not a child of the universe")
+    }
+    if fmt.Sprint(top.Names()) != "[a f]" {
+        t.Errorf("program scope names wrong, got=%v", top.Names())
+    }
+
+    fl := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+    fn := info.Scopes[fl]
+    if fn == nil || fn.Parent != top || info.Scopes[fl.Body] != fn {
+        t.Fatalf("function scope not linked correctly")
+    }
+    if fmt.Sprint(fn.Names()) != "[x y z]" {
+        t.Errorf("function scope names wrong, got=%v", fn.Names())
+    }
+    if fn.Lookup("x").Kind != Param || fn.Lookup("z").Kind != Let {
+        t.Errorf("wrong object kinds in function scope")
+    }
+
+    if len(fn.Children) != 1 || fmt.Sprint(fn.Children[0].Names()) != "[w]" {
+        t.Fatalf("block scope wrong, got=%v", fn.Children)
+    }
+    if fn.Children[0].LookupParent("a") != top.Lookup("a") {
+        t.Errorf("LookupParent did not find a in the program scope")
+    }
+}
+
+func TestResolveCorpus(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+        info, _ := Resolve(program)
+
+        ast.Inspect(program, func(n ast.Node) bool {
+            if id, ok := n.(*ast.Identifier); ok && info.Defs[id] != nil && info.Uses[id] != nil {
+                t.Errorf("%q: %s is both a definition and a use", input, id.Value)
+            }
+            return true
+        })
+    }
+}
@@ -0,0 +1,268 @@
+// Package sarif writes diagnostics in the Static Analysis Results
+// Interchange Format (SARIF) 2.1.0.
+//
+// Only the parts of the format needed to report findings against source
+// files are modelled: one run per log, rule metadata, results with a
+// physical location and partial fingerprints, and the analyzed
+// artifacts.
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+const (
+    Version = "2.1.0"
+    Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Levels of a result.
+const (
+    None    = "none"
+    Note    = "note"
+    Warning = "warning"
+    Error   = "error"
+)
+
+type Log struct {
+    Version string  `json:"version"`
+    Schema  string  `json:"$schema"`
+    Runs    []*Run  `json:"runs"`
+}
+
+type Run struct {
+    Tool        Tool        `json:"tool"`
+    Artifacts   []Artifact  `json:"artifacts,omitempty"`
+    Results     []Result    `json:"results"`
+    ColumnKind  string      `json:"columnKind"`
+}
+
+type Tool struct {
+    Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+    Name            string  `json:"name"`
+    Version         string  `json:"version,omitempty"`
+    InformationURI  string  `json:"informationUri,omitempty"`
+    Rules           []Rule  `json:"rules"`
+}
+
+// Rule is a SARIF reportingDescriptor.
+type Rule struct {
+    ID                      string          `json:"id"`
+    ShortDescription        *Message        `json:"shortDescription,omitempty"`
+    DefaultConfiguration    *Configuration  `json:"defaultConfiguration,omitempty"`
+}
+
+type Configuration struct {
+    Level string `json:"level"`
+}
+
+type Message struct {
+    Text string `json:"text"`
+}
+
+type Artifact struct {
+    Location ArtifactLocation `json:"location"`
+}
+
+type ArtifactLocation struct {
+    URI string `json:"uri"`
+}
+
+type Result struct {
+    RuleID              string              `json:"ruleId"`
+    RuleIndex           int                 `json:"ruleIndex"`
+    Level               string              `json:"level"`
+    Message             Message             `json:"message"`
+    Locations           []Location          `json:"locations"`
+    PartialFingerprints map[string]string   `json:"partialFingerprints"`
+}
+
+type Location struct {
+    PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+    ArtifactLocation    ArtifactLocation    `json:"artifactLocation"`
+    Region              *Region             `json:"region,omitempty"`
+}
+
+// Region locates a result in a file. Lines and columns are 1-based and
+// columns count Unicode code points. EndColumn is the column just after
+// the region; without an end, the region runs to the end of its line.
+type Region struct {
+    StartLine   int         `json:"startLine"`
+    StartColumn int         `json:"startColumn,omitempty"`
+    EndLine     int         `json:"endLine,omitempty"`
+    EndColumn   int         `json:"endColumn,omitempty"`
+    Snippet     *Message    `json:"snippet,omitempty"`
+}
+
+// FileURI converts a file path to the URI of an artifact: relative
+// paths stay relative, absolute ones become file URIs.
+func FileURI(path string) string {
+    u := url.URL{Path: filepath.ToSlash(path)}
+    if filepath.IsAbs(path) {
+        u.Scheme = "file"
+    }
+    return u.String()
+}
+
+// Write writes log to w as indented JSON.
+func (l *Log) Write(w io.Writer) error {
+    data, err := json.MarshalIndent(l, "", "  ")
+    if err != nil {
+        return err
+    }
+    _, err = w.Write(append(data, '\n'))
+    return err
+}
+
+// A Builder builds a log with a single run of one tool.
+type Builder struct {
+    run         *Run
+    rules       map[string]int
+    sources     map[string][]string
+    // occurrences counts results per line hash, for fingerprints
+    occurrences map[string]int
+}
+
+func NewBuilder(name, version string) *Builder {
+    return &Builder{
+        run: &Run{
+            Tool:       Tool{Driver: Driver{Name: name, Version: version, Rules: []Rule{}}},
+            Results:    []Result{},
+            ColumnKind: "unicodeCodePoints",
+        },
+        rules:       map[string]int{},
+        sources:     map[string][]string{},
+        occurrences: map[string]int{},
+    }
+}
+
+// AddRule describes a rule that results may refer to. level is the
+// level of its results unless they give their own.
+func (b *Builder) AddRule(id, description, level string) {
+    if _, ok := b.rules[id]; ok {
+        return
+    }
+    b.rules[id] = len(b.run.Tool.Driver.Rules)
+    b.run.Tool.Driver.Rules = append(b.run.Tool.Driver.Rules, Rule{
+        ID:                   id,
+        ShortDescription:     &Message{Text: description},
+        DefaultConfiguration: &Configuration{Level: level},
+    })
+}
+
+// AddArtifact records that the file at uri with contents src was
+// analyzed. Results in it get snippets, code point columns and
+// fingerprints based on the text of their line.
+func (b *Builder) AddArtifact(uri, src string) {
+    if _, ok := b.sources[uri]; ok {
+        return
+    }
+    b.sources[uri] = strings.Split(src, "\n")
+    b.run.Artifacts = append(b.run.Artifacts, Artifact{Location: ArtifactLocation{URI: uri}})
+}
+
+// AddResult records a result of rule in the file at uri, from pos up to
+// end, which is just after the reported text. An invalid end extends the
+// result to the end of pos's line. An empty level uses the rule's
+// default. The rule must have been added.
+func (b *Builder) AddResult(ruleID, level, message, uri string, pos, end token.Position) {
+    index, ok := b.rules[ruleID]
+    if !ok {
+        panic(fmt.Sprintf("sarif: result for unknown rule %q", ruleID))
+    }
+    if level == "" {
+        level = b.run.Tool.Driver.Rules[index].DefaultConfiguration.Level
+    }
+
+    location := PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: uri}}
+    lineText := ""
+    if pos.IsValid() {
+        region := &Region{StartLine: pos.Line, StartColumn: pos.Column}
+        if end.IsValid() {
+            region.EndLine, region.EndColumn = end.Line, end.Column
+        }
+        if lines := b.sources[uri]; pos.Line <= len(lines) {
+            lineText = line(lines, pos.Line)
+            setSnippet(region, lines, pos, end)
+        }
+        location.Region = region
+    }
+
+    b.run.Results = append(b.run.Results, Result{
+        RuleID:              ruleID,
+        RuleIndex:           index,
+        Level:               level,
+        Message:             Message{Text: message},
+        Locations:           []Location{{PhysicalLocation: location}},
+        PartialFingerprints: map[string]string{"primaryLocationLineHash": b.fingerprint(ruleID, uri, lineText)},
+    })
+}
+
+// line returns line n of lines, without its line terminator.
+func line(lines []string, n int) string {
+    return strings.TrimSuffix(lines[n-1], "\r")
+}
+
+// setSnippet converts the columns of region to code points and sets its
+// snippet to the text from pos up to end, or to the end of pos's line.
+func setSnippet(region *Region, lines []string, pos, end token.Position) {
+    first := line(lines, pos.Line)
+    region.Snippet = &Message{Text: first}
+    if pos.Column-1 > len(first) {
+        return
+    }
+    region.StartColumn = utf8.RuneCountInString(first[:pos.Column-1]) + 1
+    region.Snippet.Text = first[pos.Column-1:]
+
+    if !end.IsValid() || end.Line < pos.Line || end.Line > len(lines) {
+        return
+    }
+    last := line(lines, end.Line)
+    if end.Column-1 > len(last) || (end.Line == pos.Line && end.Column < pos.Column) {
+        return
+    }
+    region.EndColumn = utf8.RuneCountInString(last[:end.Column-1]) + 1
+
+    if end.Line == pos.Line {
+        region.Snippet.Text = first[pos.Column-1 : end.Column-1]
+        return
+    }
+    text := []string{first[pos.Column-1:]}
+    for n := pos.Line + 1; n < end.Line; n++ {
+        text = append(text, line(lines, n))
+    }
+    text = append(text, last[:end.Column-1])
+    region.Snippet.Text = strings.Join(text, "\n")
+}
+
+// fingerprint identifies a result by its rule, file and the text of its
+// line, so it survives edits elsewhere in the file. Repeats of the same
+// hash are numbered, as in "3f2a...:2".
+func (b *Builder) fingerprint(ruleID, uri, lineText string) string {
+    sum := sha256.Sum256([]byte(ruleID + "\x00" + uri + "\x00" + strings.TrimSpace(lineText)))
+    hash := hex.EncodeToString(sum[:8])
+
+    b.occurrences[hash]++
+    return fmt.Sprintf("%s:%d", hash, b.occurrences[hash])
+}
+
+// Log returns the log built so far.
+func (b *Builder) Log() *Log {
+    return &Log{Version: Version, Schema: Schema, Runs: []*Run{b.run}}
+}
@@ -0,0 +1,135 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+func TestBuilder(t *testing.T) {
+    b := NewBuilder("monkey", "1.0")
+    b.AddRule("parse-error", "reports source that does not parse", Error)
+    b.AddRule("unused-let", "reports unused bindings", Warning)
+    b.AddRule("unused-let", "duplicate", Note)
+    b.AddArtifact("main.mk", "let x = 1;\n// é\nlet é = 2;\n")
+
+    b.AddResult("unused-let", "", "x declared and not used", "main.mk",
+        token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6})
+    b.AddResult("parse-error", Note, "bad", "main.mk", token.Position{Offset: 21, Line: 3, Column: 7}, token.Position{})
+    b.AddResult("parse-error", "", "unpositioned", "other.mk", token.Position{}, token.Position{})
+    b.AddResult("unused-let", "", "multiline", "main.mk",
+        token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 23, Line: 3, Column: 7})
+
+    log := b.Log()
+    if log.Version != "2.1.0" || len(log.Runs) != 1 {
+        t.Fatalf("wrong log header: %+v", log)
+    }
+
+    run := log.Runs[0]
+    if len(run.Tool.Driver.Rules) != 2 {
+        t.Fatalf("expected 2 rules, got %d", len(run.Tool.Driver.Rules))
+    }
+    if len(run.Artifacts) != 1 || run.Artifacts[0].Location.URI != "main.mk" {
+        t.Errorf("wrong artifacts: %+v", run.Artifacts)
+    }
+
+    tests := []struct {
+        ruleIndex   int
+        level       string
+        region      *Region
+    }{
+        {1, Warning, &Region{StartLine: 1, StartColumn: 5, EndLine: 1, EndColumn: 6, Snippet: &Message{Text: "x"}}},
+        // without an end the region runs to the end of the line
+        {0, Note, &Region{StartLine: 3, StartColumn: 6, Snippet: &Message{Text: " = 2;"}}},
+        {0, Error, nil},
+        {1, Warning, &Region{StartLine: 1, StartColumn: 9, EndLine: 3, EndColumn: 6, Snippet: &Message{Text: "1;\n// é\nlet é"}}},
+    }
+
+    for i, tt := range tests {
+        r := run.Results[i]
+        if r.RuleIndex != tt.ruleIndex || r.Level != tt.level {
+            t.Errorf("results[%d] wrong rule or level: %d %s", i, r.RuleIndex, r.Level)
+        }
+
+        region := r.Locations[0].PhysicalLocation.Region
+        got, _ := json.Marshal(region)
+        expected, _ := json.Marshal(tt.region)
+        if !bytes.Equal(got, expected) {
+            t.Errorf("results[%d] wrong region. expected=%s, got=%s", i, expected, got)
+        }
+    }
+}
+
+func TestFingerprints(t *testing.T) {
+    b := NewBuilder("monkey", "")
+    b.AddRule("r", "", Warning)
+    b.AddArtifact("a.mk", "x == x\n  x == x\ny")
+
+    b.AddResult("r", "", "", "a.mk", token.Position{Line: 1, Column: 1}, token.Position{})
+    b.AddResult("r", "", "", "a.mk", token.Position{Line: 2, Column: 3}, token.Position{})
+    b.AddResult("r", "", "", "a.mk", token.Position{Line: 3, Column: 1}, token.Position{})
+
+    results := b.Log().Runs[0].Results
+    fp := func(i int) string {
+        return results[i].PartialFingerprints["primaryLocationLineHash"]
+    }
+
+    h0, n0, _ := strings.Cut(fp(0), ":")
+    h1, n1, _ := strings.Cut(fp(1), ":")
+    h2, _, _ := strings.Cut(fp(2), ":")
+
+    if h0 != h1 || n0 != "1" || n1 != "2" {
+        t.Errorf("lines with the same text should share a hash and be numbered: %s %s", fp(0), fp(1))
+    }
+    if h2 == h0 {
+        t.Errorf("lines with different text should not share a hash")
+    }
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+    defer func() {
+        if recover() == nil {
+            t.Errorf("expected a panic")
+        }
+    }()
+    NewBuilder("monkey", "").AddResult("nope", "", "", "a.mk", token.Position{}, token.Position{})
+}
+
+func TestFileURI(t *testing.T) {
+    tests := []struct {
+        path        string
+        expected    string
+    }{
+        {"main.mk", "main.mk"},
+        {"dir/a b.mk", "dir/a%20b.mk"},
+        {"/tmp/main.mk", "file:///tmp/main.mk"},
+    }
+
+    for _, tt := range tests {
+        if got := FileURI(tt.path); got != tt.expected {
+            t.Errorf("FileURI(%q) = %q, expected %q", tt.path, got, tt.expected)
+        }
+    }
+}
+
+func TestWrite(t *testing.T) {
+    b := NewBuilder("monkey", "")
+    var buf bytes.Buffer
+    if err := b.Log().Write(&buf); err != nil {
+        t.Fatal(err)
+    }
+
+    var decoded map[string]interface{}
+    if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+        t.Fatalf("output is not JSON: %s", err)
+    }
+    if decoded["version"] != "2.1.0" || decoded["$schema"] != Schema {
+        t.Errorf("wrong header: %v", decoded)
+    }
+    if !strings.Contains(buf.String(), `"results": []`) {
+        t.Errorf("empty results should be written as an empty array:\n%s", buf.String())
+    }
+}
@@ -1,10 +1,32 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
+// Position is a location in the source; Offset is in bytes and Line
+// and Column start at 1. The zero Position is not a valid location.
+type Position struct {
+    Offset  int
+    Line    int
+    Column  int
+}
+
+func (p Position) IsValid() bool {
+    return p.Line > 0
+}
+
+func (p Position) String() string {
+    if !p.IsValid() {
+        return "-"
+    }
+    return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
     Type    TokenType
     Literal string
+    Pos     Position
 }
 
 const (
@@ -35,6 +57,17 @@ const (
     RETURN      = "RETURN"
     EQ          = "=="
     UNEQ        = "!="
+    ARROW       = "=>"
+    PIPE        = "|>"
+    DOT         = "."
+    OPTDOT      = "?."
+    NULLISH     = "??"
+    NULL        = "NULL"
+    COLON       = ":"
+    THINARROW   = "->"
+
+    // $identifier, only in templates
+    PLACEHOLDER = "PLACEHOLDER"
 )
 
 var keywords = map[string]TokenType{
@@ -45,6 +78,7 @@ var keywords = map[string]TokenType{
     "false":    FALSE,
     "else":     ELSE,
     "return":   RETURN,
+    "null":     NULL,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,438 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/token"
+)
+
+// Info holds the types inferred for a program.
+type Info struct {
+    // Types maps every expression, including declaring identifiers
+    // such as let names and parameters, to its type. The types may
+    // contain variables bound later in inference; use TypeOf.
+    Types map[ast.Expression]Type
+}
+
+// TypeOf returns the inferred type of e, or nil if e was not checked.
+func (info *Info) TypeOf(e ast.Expression) Type {
+    t, ok := info.Types[e]
+    if !ok {
+        return nil
+    }
+    return resolved(t)
+}
+
+// An Error is a type error at the position of the offending expression.
+type Error struct {
+    Pos token.Position
+    Msg string
+}
+
+func (e *Error) Error() string {
+    return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Check infers the types of program. builtins gives the types of names
+// defined outside the program; their type variables are generalized,
+// so a builtin of type fn('a) -> int accepts any argument. Names that
+// are not defined anywhere get a fresh type, leaving undefined names
+// to the resolve package. Type annotations are checked against the
+// inferred types; int, bool and function types are known.
+func Check(program *ast.Program, builtins map[string]Type) (*Info, []*Error) {
+    c := &checker{
+        info:   &Info{Types: map[ast.Expression]Type{}},
+        errors: []*Error{},
+        scope:  &scope{names: map[string]*scheme{}},
+    }
+
+    for name, t := range builtins {
+        c.scope.names[name] = c.generalize(t)
+    }
+
+    c.level++
+    c.scope = &scope{parent: c.scope, names: map[string]*scheme{}}
+    c.statements(program.Statements)
+
+    return c.info, c.errors
+}
+
+type scope struct {
+    parent  *scope
+    names   map[string]*scheme
+}
+
+func (s *scope) lookup(name string) *scheme {
+    for ; s != nil; s = s.parent {
+        if sc, ok := s.names[name]; ok {
+            return sc
+        }
+    }
+    return nil
+}
+
+type checker struct {
+    info    *Info
+    errors  []*Error
+    scope   *scope
+    level   int
+    // result is the result type of the function being checked, or nil
+    // at the top level
+    result  Type
+}
+
+func (c *checker) errorf(n ast.Node, format string, args ...interface{}) {
+    c.errors = append(c.errors, &Error{Pos: ast.Pos(n), Msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) fresh() *Var {
+    return &Var{level: c.level}
+}
+
+// generalize quantifies t over the variables created at a deeper level
+// than the current one, which nothing in scope refers to.
+func (c *checker) generalize(t Type) *scheme {
+    sc := &scheme{t: t}
+    var collect func(t Type)
+    collect = func(t Type) {
+        switch t := prune(t).(type) {
+        case *Var:
+            if t.level > c.level {
+                for _, v := range sc.vars {
+                    if v == t {
+                        return
+                    }
+                }
+                sc.vars = append(sc.vars, t)
+            }
+        case *Func:
+            for _, p := range t.Params {
+                collect(p)
+            }
+            collect(t.Result)
+        }
+    }
+    collect(t)
+    return sc
+}
+
+// instantiate returns a copy of the scheme's type with fresh variables.
+func (c *checker) instantiate(sc *scheme) Type {
+    if len(sc.vars) == 0 {
+        return sc.t
+    }
+
+    fresh := map[*Var]Type{}
+    for _, v := range sc.vars {
+        fresh[v] = c.fresh()
+    }
+
+    var copy func(t Type) Type
+    copy = func(t Type) Type {
+        switch t := prune(t).(type) {
+        case *Var:
+            if f, ok := fresh[t]; ok {
+                return f
+            }
+            return t
+        case *Func:
+            params := []Type{}
+            for _, p := range t.Params {
+                params = append(params, copy(p))
+            }
+            return &Func{Params: params, Result: copy(t.Result)}
+        default:
+            return t
+        }
+    }
+    return copy(sc.t)
+}
+
+// expect unifies the type got of n with want, reporting a mismatch.
+func (c *checker) expect(n ast.Node, want, got Type) {
+    err := unify(want, got)
+    if err == nil {
+        return
+    }
+
+    names := map[*Var]string{}
+    msg := fmt.Sprintf("type mismatch in %s: expected %s, got %s",
+        n.String(), typeString(want, names), typeString(got, names))
+    if err.msg != "" {
+        msg += " (" + err.msg + ")"
+    }
+    c.errors = append(c.errors, &Error{Pos: ast.Pos(n), Msg: msg})
+}
+
+func (c *checker) openScope() {
+    c.scope = &scope{parent: c.scope, names: map[string]*scheme{}}
+}
+
+func (c *checker) closeScope() {
+    c.scope = c.scope.parent
+}
+
+// statements checks list and returns its value's type: that of its last
+// statement if it is an expression, and a fresh type, for null,
+// otherwise.
+func (c *checker) statements(list []ast.Statement) Type {
+    var last Type = c.fresh()
+
+    for i, s := range list {
+        t := c.statement(s)
+        if i == len(list)-1 && t != nil {
+            last = t
+        }
+    }
+
+    return last
+}
+
+func (c *checker) statement(s ast.Statement) Type {
+    switch s := s.(type) {
+    case *ast.LetStatement:
+        c.let(s)
+
+    case *ast.ReturnStatement:
+        t := c.expression(s.ReturnValue)
+        if c.result != nil {
+            c.expect(s.ReturnValue, c.result, t)
+        }
+
+    case *ast.ExpressionStatement:
+        return c.expression(s.Expression)
+    }
+
+    return nil
+}
+
+// let checks a let statement. A function value may refer to itself, so
+// the name is bound to a monomorphic type while the value is checked
+// and generalized afterwards.
+func (c *checker) let(s *ast.LetStatement) {
+    c.level++
+
+    var declared Type
+    if s.Name != nil && s.Name.Annotation != nil {
+        declared = c.annotation(s.Name.Annotation)
+    }
+
+    var self Type
+    if _, ok := s.Value.(*ast.FunctionLiteral); ok && s.Name != nil {
+        self = c.fresh()
+        if declared != nil {
+            self = declared
+        }
+        c.openScope()
+        c.scope.names[s.Name.Value] = &scheme{t: self}
+    }
+
+    t := c.expression(s.Value)
+
+    if self != nil {
+        c.closeScope()
+        c.expect(s.Value, self, t)
+    } else if declared != nil {
+        c.expect(s.Value, declared, t)
+    }
+
+    c.level--
+
+    if s.Name != nil {
+        c.info.Types[s.Name] = t
+        c.scope.names[s.Name.Value] = c.generalize(t)
+    }
+}
+
+func (c *checker) block(b *ast.BlockStatement) Type {
+    if b == nil {
+        return c.fresh()
+    }
+
+    c.openScope()
+    t := c.statements(b.Statements)
+    c.closeScope()
+
+    c.info.Types[b] = t
+    return t
+}
+
+func (c *checker) expression(e ast.Expression) Type {
+    if e == nil {
+        return c.fresh()
+    }
+
+    t := c.infer(e)
+    c.info.Types[e] = t
+    return t
+}
+
+func (c *checker) infer(e ast.Expression) Type {
+    switch e := e.(type) {
+    case *ast.IntegerLiteral:
+        return Int
+
+    case *ast.Boolean:
+        return Bool
+
+    case *ast.NullLiteral, *ast.Placeholder:
+        return c.fresh()
+
+    case *ast.Identifier:
+        if sc := c.scope.lookup(e.Value); sc != nil {
+            return c.instantiate(sc)
+        }
+        return c.fresh()
+
+    case *ast.PrefixExpression:
+        right := c.expression(e.Right)
+        if e.Operator == "-" {
+            c.expect(e.Right, Int, right)
+            return Int
+        }
+        return Bool
+
+    case *ast.InfixExpression:
+        left := c.expression(e.Left)
+        right := c.expression(e.Right)
+
+        switch e.Operator {
+        case "+", "-", "*", "/":
+            c.expect(e.Left, Int, left)
+            c.expect(e.Right, Int, right)
+            return Int
+        case "<", ">":
+            c.expect(e.Left, Int, left)
+            c.expect(e.Right, Int, right)
+            return Bool
+        case "==", "!=":
+            c.expect(e.Right, left, right)
+            return Bool
+        case "??":
+            c.expect(e.Right, left, right)
+            return left
+        }
+        return c.fresh()
+
+    case *ast.IfExpression:
+        c.expression(e.Condition)
+        consequence := c.block(e.Consequence)
+        if e.Alternative != nil {
+            c.expect(e.Alternative, consequence, c.block(e.Alternative))
+        }
+        return consequence
+
+    case *ast.FunctionLiteral:
+        return c.function(e)
+
+    case *ast.CallExpression:
+        return c.call(e, e.Function, e.Arguments)
+
+    case *ast.PipeExpression:
+        call := e.Call()
+        return c.call(e, call.Function, call.Arguments)
+
+    case *ast.MemberExpression:
+        // Monkey has no record types, so nothing is known about fields
+        c.expression(e.Object)
+        return c.fresh()
+
+    case *ast.BlockStatement:
+        return c.block(e)
+    }
+
+    return c.fresh()
+}
+
+func (c *checker) function(fl *ast.FunctionLiteral) Type {
+    c.openScope()
+
+    params := []Type{}
+    for _, p := range fl.Parameters {
+        var t Type = c.fresh()
+        if p.Annotation != nil {
+            t = c.annotation(p.Annotation)
+        }
+        params = append(params, t)
+        c.scope.names[p.Value] = &scheme{t: t}
+        c.info.Types[p] = t
+    }
+
+    var result Type = c.fresh()
+    if fl.Result != nil {
+        result = c.annotation(fl.Result)
+    }
+    outer := c.result
+    c.result = result
+
+    if fl.Body != nil {
+        body := c.statements(fl.Body.Statements)
+        c.info.Types[fl.Body] = body
+        if n := len(fl.Body.Statements); n > 0 {
+            if es, ok := fl.Body.Statements[n-1].(*ast.ExpressionStatement); ok {
+                c.expect(es.Expression, result, body)
+            }
+        }
+    }
+
+    c.result = outer
+    c.closeScope()
+
+    return &Func{Params: params, Result: result}
+}
+
+// annotation returns the type written as t. Unknown types are reported
+// and stand for a fresh type.
+func (c *checker) annotation(t ast.TypeExpr) Type {
+    switch t := t.(type) {
+    case *ast.NamedType:
+        switch t.Name {
+        case "int":
+            return Int
+        case "bool":
+            return Bool
+        }
+
+    case *ast.FuncType:
+        params := []Type{}
+        for _, p := range t.Params {
+            params = append(params, c.annotation(p))
+        }
+        return &Func{Params: params, Result: c.annotation(t.Result)}
+    }
+
+    c.errorf(t, "unknown type %s", t.String())
+    return c.fresh()
+}
+
+// call checks a call of function with args; n is the call, or the pipe
+// expression that stands for it.
+func (c *checker) call(n ast.Expression, function ast.Expression, args []ast.Expression) Type {
+    ft := c.expression(function)
+
+    argTypes := []Type{}
+    for _, a := range args {
+        argTypes = append(argTypes, c.expression(a))
+    }
+
+    switch f := prune(ft).(type) {
+    case *Basic:
+        c.errorf(n, "cannot call %s of type %s", function.String(), f)
+        return c.fresh()
+    case *Func:
+        if len(f.Params) != len(args) {
+            c.errorf(n, "wrong number of arguments in call to %s: expected %d, got %d",
+                function.String(), len(f.Params), len(args))
+            return f.Result
+        }
+        for i, a := range args {
+            c.expect(a, f.Params[i], argTypes[i])
+        }
+        return f.Result
+    }
+
+    result := c.fresh()
+    c.expect(function, &Func{Params: argTypes, Result: result}, ft)
+    return result
+}
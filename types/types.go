@@ -0,0 +1,205 @@
+// Package types infers the types of Monkey expressions.
+//
+// Types are inferred with Hindley–Milner type inference: every
+// expression gets the most general type consistent with how it is
+// used, and functions bound by let are polymorphic, so
+//
+//    let id = fn(x) { x };
+//    id(1); id(true);
+//
+// checks, with id of type fn('a) -> 'a. Conditions may be of any type,
+// since Monkey treats every value as true or false, and null may stand
+// for a value of any type.
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// A Type is a Basic, a Func or a type variable.
+type Type interface {
+    String()    string
+    typ()
+}
+
+// A Basic is a type without parts.
+type Basic struct {
+    Name string
+}
+
+var (
+    Int     = &Basic{Name: "int"}
+    Bool    = &Basic{Name: "bool"}
+)
+
+// A Func is the type of a function.
+type Func struct {
+    Params  []Type
+    Result  Type
+}
+
+// A Var is a type variable, standing for a type not known yet. Once
+// unified with a type it becomes an alias for it.
+type Var struct {
+    level       int
+    instance    Type
+}
+
+// genericLevel is the level of variables made by NewVar, deeper than
+// any scope, so they are always generalized.
+const genericLevel = math.MaxInt32
+
+// NewVar returns a type variable for use in the types of builtins.
+func NewVar() *Var {
+    return &Var{level: genericLevel}
+}
+
+func (*Basic) typ() {}
+func (*Func) typ()  {}
+func (*Var) typ()   {}
+
+func (b *Basic) String() string {
+    return b.Name
+}
+
+func (f *Func) String() string {
+    return typeString(f, map[*Var]string{})
+}
+
+func (v *Var) String() string {
+    return typeString(v, map[*Var]string{})
+}
+
+// typeString formats t, naming its unbound variables 'a, 'b, ... in the
+// order they appear.
+func typeString(t Type, names map[*Var]string) string {
+    switch t := prune(t).(type) {
+    case *Basic:
+        return t.Name
+    case *Var:
+        name, ok := names[t]
+        if !ok {
+            name = varName(len(names))
+            names[t] = name
+        }
+        return name
+    case *Func:
+        params := []string{}
+        for _, p := range t.Params {
+            params = append(params, typeString(p, names))
+        }
+        return "fn(" + strings.Join(params, ", ") + ") -> " + typeString(t.Result, names)
+    }
+    return "?"
+}
+
+func varName(i int) string {
+    name := string(rune('a' + i%26))
+    if i >= 26 {
+        name += fmt.Sprint(i / 26)
+    }
+    return "'" + name
+}
+
+// prune returns the type a chain of bound variables stands for.
+func prune(t Type) Type {
+    for {
+        v, ok := t.(*Var)
+        if !ok || v.instance == nil {
+            return t
+        }
+        t = v.instance
+    }
+}
+
+// resolved returns t with every bound variable replaced by its type.
+func resolved(t Type) Type {
+    switch t := prune(t).(type) {
+    case *Func:
+        params := []Type{}
+        for _, p := range t.Params {
+            params = append(params, resolved(p))
+        }
+        return &Func{Params: params, Result: resolved(t.Result)}
+    default:
+        return t
+    }
+}
+
+// A scheme is a type generalized over some of its variables.
+type scheme struct {
+    vars    []*Var
+    t       Type
+}
+
+// occurs reports whether v occurs in t, lowering the level of the
+// variables of t to that of v on the way, since they become reachable
+// from wherever v is.
+func occurs(v *Var, t Type) bool {
+    switch t := prune(t).(type) {
+    case *Var:
+        if t == v {
+            return true
+        }
+        if t.level > v.level {
+            t.level = v.level
+        }
+    case *Func:
+        for _, p := range t.Params {
+            if occurs(v, p) {
+                return true
+            }
+        }
+        return occurs(v, t.Result)
+    }
+    return false
+}
+
+// unifyError describes why two types do not unify.
+type unifyError struct {
+    msg string
+}
+
+// unify makes a and b the same type by binding variables.
+func unify(a, b Type) *unifyError {
+    a, b = prune(a), prune(b)
+
+    if va, ok := a.(*Var); ok {
+        if vb, ok := b.(*Var); ok && va == vb {
+            return nil
+        }
+        if occurs(va, b) {
+            return &unifyError{"infinite type"}
+        }
+        va.instance = b
+        return nil
+    }
+    if _, ok := b.(*Var); ok {
+        return unify(b, a)
+    }
+
+    switch a := a.(type) {
+    case *Basic:
+        if b, ok := b.(*Basic); ok && a == b {
+            return nil
+        }
+    case *Func:
+        b, ok := b.(*Func)
+        if !ok {
+            break
+        }
+        if len(a.Params) != len(b.Params) {
+            return &unifyError{fmt.Sprintf("functions take %d and %d arguments", len(a.Params), len(b.Params))}
+        }
+        for i := range a.Params {
+            if err := unify(a.Params[i], b.Params[i]); err != nil {
+                return err
+            }
+        }
+        return unify(a.Result, b.Result)
+    }
+
+    return &unifyError{}
+}
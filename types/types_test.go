@@ -0,0 +1,175 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/UsamaHameed/monkey-interpreter/ast"
+	"github.com/UsamaHameed/monkey-interpreter/internal/corpus"
+	"github.com/UsamaHameed/monkey-interpreter/internal/testutil"
+)
+
+// lastType returns the type of the value of the last statement of the
+// program, or of the last let binding.
+func lastType(t *testing.T, input string, builtins map[string]Type) (Type, []*Error) {
+    program := testutil.Parse(t, input)
+    info, errs := Check(program, builtins)
+
+    switch s := program.Statements[len(program.Statements)-1].(type) {
+    case *ast.ExpressionStatement:
+        return info.TypeOf(s.Expression), errs
+    case *ast.LetStatement:
+        return info.TypeOf(s.Name), errs
+    case *ast.ReturnStatement:
+        return info.TypeOf(s.ReturnValue), errs
+    }
+    return nil, errs
+}
+
+func TestInference(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    string
+    }{
+        {"5", "int"},
+        {"true", "bool"},
+        {"-5", "int"},
+        {"!5", "bool"},
+        {"1 + 2 * 3", "int"},
+        {"1 < 2", "bool"},
+        {"true == false", "bool"},
+        {"null", "'a"},
+        {"null ?? 5", "int"},
+        {"if (x) { 1 } else { 2 }", "int"},
+        {"if (true) { 1 }", "int"},
+        {"fn(x) { x }", "fn('a) -> 'a"},
+        {"fn(x, y) { x + y }", "fn(int, int) -> int"},
+        {"fn(f, x) { f(x) }", "fn(fn('a) -> 'b, 'a) -> 'b"},
+        {"fn(x) { if (x < 1) { return true; } false }", "fn(int) -> bool"},
+        {"x => x == 1", "fn(int) -> bool"},
+        {"let compose = (f, g) => x => g(f(x));", "fn(fn('a) -> 'b, fn('b) -> 'c) -> fn('a) -> 'c"},
+        {"let id = fn(x) { x }; id(1); id(true)", "bool"},
+        {"let id = fn(x) { x }; let pair = fn(a, b) { a }; pair(id(1), id(true))", "int"},
+        {"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };", "fn(int) -> int"},
+        {"let inc = x => x + 1; 1 |> inc", "int"},
+        {"let add = fn(a, b) { a + b }; 1 |> add(2)", "int"},
+        {"let f = fn() { let x = 1; }; f()", "'a"},
+        {"fn(o) { o.name }", "fn('a) -> 'b"},
+        {"fn(y) { let g = fn(x) { y }; g(1); g(true) + 1 }", "fn(int) -> int"},
+        {"let apply = fn(f) { fn(x) { f(f(x)) } }; apply(fn(n) { n * 2 })", "fn(int) -> int"},
+        {"let x: int = 5;", "int"},
+        {"fn(x: int) { x }", "fn(int) -> int"},
+        {"(x: bool, y) => y", "fn(bool, 'a) -> 'a"},
+        {"fn(x) -> bool { x }", "fn(bool) -> bool"},
+        {"fn(f: fn(int) -> bool, x) { f(x) }", "fn(fn(int) -> bool, int) -> bool"},
+        {"let f: fn(int) -> int = fn(n) { if (n < 1) { 0 } else { f(n - 1) } };", "fn(int) -> int"},
+    }
+
+    for _, tt := range tests {
+        got, errs := lastType(t, tt.input, nil)
+        if len(errs) != 0 {
+            t.Errorf("%q: unexpected errors %v", tt.input, errs)
+            continue
+        }
+        if got == nil || got.String() != tt.expected {
+            t.Errorf("%q: wrong type. expected=%s, got=%v", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestErrors(t *testing.T) {
+    tests := []struct {
+        input       string
+        expected    []string
+    }{
+        {"true + 1", []string{"1:1: type mismatch in true: expected int, got bool"}},
+        {"1 == true", []string{"1:6: type mismatch in true: expected int, got bool"}},
+        {"let x = 5; x(1)", []string{"1:12: cannot call x of type int"}},
+        {"let f = fn(a) { a }; f(1, 2)", []string{"1:22: wrong number of arguments in call to f: expected 1, got 2"}},
+        {"if (x) { 1 } else { false }", []string{"1:19: type mismatch in false: expected int, got bool"}},
+        {"fn(x) { if (x) { return 1; } true }", []string{"1:30: type mismatch in true: expected int, got bool"}},
+        {"fn(f) { f(f) }", []string{"1:9: type mismatch in f: expected fn('a) -> 'b, got 'a (infinite type)"}},
+        {"let inc = x => x + 1; true |> inc", []string{"1:23: type mismatch in true: expected int, got bool"}},
+        {"fn(g) { g(1) + g(true) }", []string{"1:18: type mismatch in true: expected int, got bool"}},
+        {"-true", []string{"1:2: type mismatch in true: expected int, got bool"}},
+        {"fn(f) { f(1); f(true) }", []string{"1:17: type mismatch in true: expected int, got bool"}},
+        {"let x: bool = 5;", []string{"1:15: type mismatch in 5: expected bool, got int"}},
+        {"fn(a: int) -> bool { a }", []string{"1:22: type mismatch in a: expected bool, got int"}},
+        {"let f: fn(int) -> int = fn(b: bool) { 1 };", []string{"1:25: type mismatch in fn(b: bool) { 1 }: expected fn(int) -> int, got fn(bool) -> int"}},
+        {"let xs: list<int> = 1;", []string{"1:9: unknown type list<int>"}},
+        {"fn(a: string) { a }", []string{"1:7: unknown type string"}},
+    }
+
+    for _, tt := range tests {
+        _, errs := Check(testutil.Parse(t, tt.input), nil)
+
+        got := []string{}
+        for _, err := range errs {
+            got = append(got, err.Error())
+        }
+        if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+            t.Errorf("%q: wrong errors.\nexpected=%q\ngot=     %q", tt.input, tt.expected, got)
+        }
+    }
+}
+
+func TestBuiltins(t *testing.T) {
+    a := NewVar()
+    builtins := map[string]Type{
+        "len":  &Func{Params: []Type{a}, Result: Int},
+        "puts": &Func{Params: []Type{NewVar()}, Result: NewVar()},
+    }
+
+    got, errs := lastType(t, "len(1) + len(true)", builtins)
+    if len(errs) != 0 || got.String() != "int" {
+        t.Errorf("wrong result: %v %v", got, errs)
+    }
+
+    _, errs = lastType(t, "if (len(1)) { puts(1) } else { 2 }", builtins)
+    if len(errs) != 0 {
+        t.Errorf("unexpected errors: %v", errs)
+    }
+}
+
+func TestTypeOfEveryExpression(t *testing.T) {
+    for _, input := range corpus.Programs {
+        program := testutil.Parse(t, input)
+        info, _ := Check(program, nil)
+
+        ast.Inspect(program, func(n ast.Node) bool {
+            if e, ok := n.(ast.Expression); ok && e != nil {
+                if info.TypeOf(e) == nil && !untyped(program, e) {
+                    t.Errorf("%q: no type for %s (%T)", input, e.String(), e)
+                }
+            }
+            return true
+        })
+    }
+}
+
+// untyped reports whether e is a member property, which is a field name,
+// or the call on the right of a pipe, which is never evaluated as a
+// call of its own.
+func untyped(program *ast.Program, e ast.Expression) bool {
+    found := false
+    ast.Inspect(program, func(n ast.Node) bool {
+        switch n := n.(type) {
+        case *ast.MemberExpression:
+            found = found || ast.Node(n.Property) == ast.Node(e)
+        case *ast.PipeExpression:
+            _, isCall := n.Right.(*ast.CallExpression)
+            found = found || (isCall && n.Right == e)
+        }
+        return !found
+    })
+    return found
+}
+
+func TestTypeString(t *testing.T) {
+    a, b := NewVar(), NewVar()
+    f := &Func{Params: []Type{a, &Func{Params: []Type{}, Result: b}}, Result: a}
+
+    if f.String() != "fn('a, fn() -> 'b) -> 'a" {
+        t.Errorf("wrong string: %s", f)
+    }
+}